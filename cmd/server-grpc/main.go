@@ -5,6 +5,7 @@ import (
 	"log"
 	"net"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -48,6 +49,11 @@ func main() {
 	if err := db.EnsureIndexes(ctx, mdb); err != nil {
 		log.Fatal(err)
 	}
+	if _, skipped, err := db.MigrateTimestamps(ctx, mdb); err != nil {
+		log.Fatal(err)
+	} else if skipped > 0 {
+		log.Printf("timestamp migration skipped %d malformed values; see above", skipped)
+	}
 
 	// --- Redis ---
 	redisAddr := getenv("REDIS_ADDR", "localhost:6379")
@@ -76,11 +82,31 @@ func main() {
 	roomRepo := repo.NewRoomRepoMongo(mdb)
 	bookingRepo := repo.NewBookingRepoMongo(mdb)
 	waitRepo := repo.NewWaitlistRepoMongo(mdb)
+	seriesRepo := repo.NewSeriesRepoMongo(mdb)
+	groupRepo := repo.NewGroupRepoMongo(mdb)
+	holidayRepo := repo.NewHolidayRepoMongo(mdb)
+	amenityRepo := repo.NewAmenityRepoMongo(mdb)
+	locationRepo := repo.NewLocationRepoMongo(mdb)
+	noteRepo := repo.NewNotificationRepoMongo(mdb)
 
 	// --- Services ---
+	bookingCfg := service.DefaultBookingConfig()
+	bookingCfg.CheckInOpensBefore = getenvDuration("CHECKIN_OPENS_BEFORE", bookingCfg.CheckInOpensBefore)
+	bookingCfg.CheckInClosesAfter = getenvDuration("CHECKIN_CLOSES_AFTER", bookingCfg.CheckInClosesAfter)
+	bookingCfg.NoShowGrace = getenvDuration("NO_SHOW_GRACE", bookingCfg.NoShowGrace)
+	bookingCfg.HoldTTL = getenvDuration("HOLD_TTL", bookingCfg.HoldTTL)
+	bookingCfg.OfferTTL = getenvDuration("WAITLIST_OFFER_TTL", bookingCfg.OfferTTL)
+	bookingCfg.MaxActiveBookings = getenvInt("QUOTA_MAX_ACTIVE", 0)
+	bookingCfg.MaxPerDay = getenvDuration("QUOTA_MAX_PER_DAY", 0)
+	bookingCfg.MaxPerWeek = getenvDuration("QUOTA_MAX_PER_WEEK", 0)
+	bookingCfg.MaxSimultaneous = getenvInt("QUOTA_MAX_SIMULTANEOUS", 0)
+
 	authSvc := service.NewAuthService(userRepo, sessRepo)
-	bookingSvc := service.NewBookingService(roomRepo, bookingRepo, waitRepo)
-	searchSvc := service.NewSearchService(roomRepo, bookingRepo)
+	bookingSvc := service.NewBookingService(roomRepo, bookingRepo, waitRepo, seriesRepo, groupRepo, holidayRepo, amenityRepo, locationRepo, userRepo, noteRepo, bookingCfg)
+	searchSvc := service.NewSearchService(roomRepo, bookingRepo, amenityRepo, locationRepo, userRepo)
+
+	// background jobs (no-show release, hold expiry)
+	go service.RunMaintenance(ctx, bookingSvc, getenvDuration("MAINTENANCE_INTERVAL", time.Minute))
 
 	// --- Raft Node ---
 	raftNode := raft.NewNode(nodeID, "localhost:"+raftPort, peers)
@@ -152,6 +178,26 @@ func getenv(k, def string) string {
 	return def
 }
 
+func getenvDuration(k string, def time.Duration) time.Duration {
+	if v := os.Getenv(k); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+		log.Printf("invalid %s=%q, using %s", k, v, def)
+	}
+	return def
+}
+
+func getenvInt(k string, def int) int {
+	if v := os.Getenv(k); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+		log.Printf("invalid %s=%q, using %d", k, v, def)
+	}
+	return def
+}
+
 func parsePeers(peersStr string) map[string]string {
 	peers := make(map[string]string)
 	if peersStr == "" {
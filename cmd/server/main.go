@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -30,6 +31,11 @@ func main() {
 	if err := mc.Ping(ctx, nil); err != nil { log.Fatal(err) }
 	mdb := mc.Database(dbName)
 	if err := db.EnsureIndexes(ctx, mdb); err != nil { log.Fatal(err) }
+	if _, skipped, err := db.MigrateTimestamps(ctx, mdb); err != nil {
+		log.Fatal(err)
+	} else if skipped > 0 {
+		log.Printf("timestamp migration skipped %d malformed values; see above", skipped)
+	}
 
 	// --- Redis ---
 	redisAddr := getenv("REDIS_ADDR", "localhost:6379")
@@ -57,11 +63,31 @@ func main() {
 	roomRepo := repo.NewRoomRepoMongo(mdb)
 	bookingRepo := repo.NewBookingRepoMongo(mdb)
 	waitRepo := repo.NewWaitlistRepoMongo(mdb)
+	seriesRepo := repo.NewSeriesRepoMongo(mdb)
+	groupRepo := repo.NewGroupRepoMongo(mdb)
+	holidayRepo := repo.NewHolidayRepoMongo(mdb)
+	amenityRepo := repo.NewAmenityRepoMongo(mdb)
+	locationRepo := repo.NewLocationRepoMongo(mdb)
+	noteRepo := repo.NewNotificationRepoMongo(mdb)
 
 	// --- Services ---
+	bookingCfg := service.DefaultBookingConfig()
+	bookingCfg.CheckInOpensBefore = getenvDuration("CHECKIN_OPENS_BEFORE", bookingCfg.CheckInOpensBefore)
+	bookingCfg.CheckInClosesAfter = getenvDuration("CHECKIN_CLOSES_AFTER", bookingCfg.CheckInClosesAfter)
+	bookingCfg.NoShowGrace = getenvDuration("NO_SHOW_GRACE", bookingCfg.NoShowGrace)
+	bookingCfg.HoldTTL = getenvDuration("HOLD_TTL", bookingCfg.HoldTTL)
+	bookingCfg.OfferTTL = getenvDuration("WAITLIST_OFFER_TTL", bookingCfg.OfferTTL)
+	bookingCfg.MaxActiveBookings = getenvInt("QUOTA_MAX_ACTIVE", 0)
+	bookingCfg.MaxPerDay = getenvDuration("QUOTA_MAX_PER_DAY", 0)
+	bookingCfg.MaxPerWeek = getenvDuration("QUOTA_MAX_PER_WEEK", 0)
+	bookingCfg.MaxSimultaneous = getenvInt("QUOTA_MAX_SIMULTANEOUS", 0)
+
 	authSvc := service.NewAuthService(userRepo, sessRepo)
-	bookingSvc := service.NewBookingService(roomRepo, bookingRepo, waitRepo)
-	searchSvc := service.NewSearchService(roomRepo, bookingRepo)
+	bookingSvc := service.NewBookingService(roomRepo, bookingRepo, waitRepo, seriesRepo, groupRepo, holidayRepo, amenityRepo, locationRepo, userRepo, noteRepo, bookingCfg)
+	searchSvc := service.NewSearchService(roomRepo, bookingRepo, amenityRepo, locationRepo, userRepo)
+
+	// background jobs (no-show release, hold expiry)
+	go service.RunMaintenance(ctx, bookingSvc, getenvDuration("MAINTENANCE_INTERVAL", time.Minute))
 
 	// --- HTTP ---
 	r := gin.Default()
@@ -76,17 +102,72 @@ func main() {
 	r.POST("/login", authH.Login)
 	r.POST("/logout", authH.Logout)
 	r.GET("/me", middleware.Auth(authSvc), authH.Me)
+	r.GET("/me/quota", middleware.Auth(authSvc), bookH.Quota)
+	r.GET("/me/favorites", middleware.Auth(authSvc), searchH.ListFavorites)
+	r.PUT("/me/favorites/:room_id", middleware.Auth(authSvc), searchH.AddFavorite)
+	r.DELETE("/me/favorites/:room_id", middleware.Auth(authSvc), searchH.RemoveFavorite)
 
 	r.POST("/bookings", middleware.Auth(authSvc), bookH.Create)
+	r.GET("/bookings", middleware.Auth(authSvc), bookH.List)
 	r.DELETE("/bookings/:id", middleware.Auth(authSvc), bookH.Cancel)
+	r.PATCH("/bookings/:id", middleware.Auth(authSvc), bookH.Reschedule)
+	r.POST("/bookings/:id/checkin", middleware.Auth(authSvc), bookH.CheckIn)
+	r.POST("/holds", middleware.Auth(authSvc), bookH.Hold)
+	r.POST("/holds/:id/confirm", middleware.Auth(authSvc), bookH.ConfirmHold)
 	r.POST("/waitlist", middleware.Auth(authSvc), bookH.JoinWaitlist)
+	r.GET("/waitlist", middleware.Auth(authSvc), bookH.ListWaitlist)
+	r.DELETE("/waitlist", middleware.Auth(authSvc), bookH.LeaveWaitlist)
+	r.POST("/waitlist/any", middleware.Auth(authSvc), bookH.JoinAnyRoomWaitlist)
+	r.DELETE("/waitlist/:id", middleware.Auth(authSvc), bookH.LeaveWaitlistEntry)
+	r.GET("/offers", middleware.Auth(authSvc), bookH.ListOffers)
+	r.POST("/offers/:id/accept", middleware.Auth(authSvc), bookH.AcceptOffer)
+	r.POST("/offers/:id/decline", middleware.Auth(authSvc), bookH.DeclineOffer)
+	r.GET("/notifications", middleware.Auth(authSvc), bookH.Notifications)
+	r.POST("/series", middleware.Auth(authSvc), bookH.CreateSeries)
+	r.GET("/series/:id", middleware.Auth(authSvc), bookH.GetSeries)
+	r.DELETE("/series/:id", middleware.Auth(authSvc), bookH.CancelSeries)
+	r.POST("/groups", middleware.Auth(authSvc), bookH.CreateGroup)
+	r.GET("/groups/:id", middleware.Auth(authSvc), bookH.GetGroup)
+	r.DELETE("/groups/:id", middleware.Auth(authSvc), bookH.CancelGroup)
 	r.GET("/search", middleware.Auth(authSvc), searchH.SearchRooms)
+	r.GET("/search/slots", middleware.Auth(authSvc), searchH.NextSlots)
+	r.GET("/timeline", middleware.Auth(authSvc), bookH.Timeline)
+	r.GET("/rooms/:id/timeline", middleware.Auth(authSvc), bookH.RoomTimeline)
+	r.GET("/amenities", middleware.Auth(authSvc), searchH.ListAmenities)
+	r.GET("/campuses", middleware.Auth(authSvc), searchH.ListCampuses)
+	r.GET("/buildings", middleware.Auth(authSvc), searchH.ListBuildings)
+	r.GET("/buildings/:id", middleware.Auth(authSvc), searchH.GetBuilding)
+	r.GET("/buildings/:id/floors", middleware.Auth(authSvc), searchH.ListFloors)
 
 	admin := r.Group("/admin", middleware.Auth(authSvc), middleware.Admin())
 	{
 		admin.POST("/rooms", adminH.CreateRoom)
 		admin.GET("/rooms", adminH.ListRooms)
-		admin.POST("/admin/rooms/:id/schedule", adminH.SetRoomSchedule)
+		admin.PATCH("/rooms/:id", adminH.UpdateRoom)
+		admin.DELETE("/rooms/:id", adminH.DeleteRoom)
+		admin.POST("/rooms/:id/archive", adminH.ArchiveRoom)
+		admin.POST("/rooms/:id/restore", adminH.RestoreRoom)
+		admin.PUT("/rooms/:id/amenities", adminH.SetRoomAmenities)
+		admin.POST("/amenities", adminH.CreateAmenity)
+		admin.DELETE("/amenities/:key", adminH.DeleteAmenity)
+		admin.POST("/campuses", adminH.CreateCampus)
+		admin.POST("/buildings", adminH.CreateBuilding)
+		admin.PUT("/buildings/:id", adminH.UpdateBuilding)
+		admin.POST("/buildings/:id/floors", adminH.CreateFloor)
+		admin.POST("/admin/rooms/:id/schedule", adminH.SetRoomSchedule) // old doubled path, kept for existing clients
+		admin.POST("/rooms/:id/schedule", adminH.SetRoomSchedule)
+		admin.GET("/rooms/:id/schedule", adminH.ListRoomSchedule)
+		admin.GET("/rooms/:id/schedule/:window_id", adminH.GetRoomSchedule)
+		admin.PUT("/rooms/:id/schedule/:window_id", adminH.UpdateRoomSchedule)
+		admin.DELETE("/rooms/:id/schedule/:window_id", adminH.DeleteRoomSchedule)
+		admin.PUT("/rooms/:id/policy", adminH.SetRoomPolicy)
+		admin.POST("/rooms/:id/hours", adminH.AddRoomHours)
+		admin.GET("/rooms/:id/hours", adminH.ListRoomHours)
+		admin.DELETE("/rooms/:id/hours/:hours_id", adminH.DeleteRoomHours)
+		admin.POST("/holidays", adminH.AddHolidays)
+		admin.POST("/holidays/import", adminH.ImportHolidays)
+		admin.GET("/holidays", adminH.ListHolidays)
+		admin.DELETE("/holidays/:id", adminH.DeleteHoliday)
 	}
 
 	log.Println("listening on http://localhost:8080")
@@ -97,3 +178,19 @@ func getenv(k, def string) string {
 	if v := os.Getenv(k); v != "" { return v }
 	return def
 }
+
+func getenvDuration(k string, def time.Duration) time.Duration {
+	if v := os.Getenv(k); v != "" {
+		if d, err := time.ParseDuration(v); err == nil { return d }
+		log.Printf("invalid %s=%q, using %s", k, v, def)
+	}
+	return def
+}
+
+func getenvInt(k string, def int) int {
+	if v := os.Getenv(k); v != "" {
+		if n, err := strconv.Atoi(v); err == nil { return n }
+		log.Printf("invalid %s=%q, using %d", k, v, def)
+	}
+	return def
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"time"
 
@@ -24,21 +25,88 @@ func EnsureIndexes(ctx context.Context, d *mongo.Database) error {
 		Options: options.Index().SetUnique(true),
 	}); err != nil { return err }
 
-	// rooms: unique name
+	// rooms: name unique within a building (rooms not yet placed share one
+	// namespace); replaces the old global unique name index
+	if _, err := d.Collection("rooms").Indexes().DropOne(ctx, "name_1"); err != nil {
+		var ce mongo.CommandError
+		if !errors.As(err, &ce) || (ce.Code != 26 && ce.Code != 27) { return err } // NamespaceNotFound, IndexNotFound
+	}
 	if _, err := d.Collection("rooms").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "building_id", Value: 1}, {Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil { return err }
+
+	// location hierarchy: names unique within their parent
+	if _, err := d.Collection("campuses").Indexes().CreateOne(ctx, mongo.IndexModel{
 		Keys:    bson.D{{Key: "name", Value: 1}},
 		Options: options.Index().SetUnique(true),
 	}); err != nil { return err }
+	if _, err := d.Collection("buildings").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "campus_id", Value: 1}, {Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil { return err }
+	if _, err := d.Collection("floors").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "building_id", Value: 1}, {Key: "level", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil { return err }
 
 	// bookings
 	if _, err := d.Collection("bookings").Indexes().CreateMany(ctx, []mongo.IndexModel{
 		{Keys: bson.D{{Key: "room_id", Value: 1}, {Key: "start_at", Value: 1}, {Key: "end_at", Value: 1}}},
 		{Keys: bson.D{{Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "series_id", Value: 1}, {Key: "start_at", Value: 1}}},
+		{Keys: bson.D{{Key: "group_id", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "status", Value: 1}, {Key: "end_at", Value: 1}}},
+		{Keys: bson.D{{Key: "attendee_ids", Value: 1}, {Key: "status", Value: 1}, {Key: "end_at", Value: 1}}},
 	}); err != nil { return err }
 
-	// waitlist FIFO per (room_id, start, end)
-	if _, err := d.Collection("waitlist").Indexes().CreateOne(ctx, mongo.IndexModel{
-		Keys: bson.D{{Key: "room_id", Value: 1}, {Key: "start_at", Value: 1}, {Key: "end_at", Value: 1}, {Key: "created_at", Value: 1}},
+	// holds are swept once they expire
+	if _, err := d.Collection("bookings").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "hold_expires_at", Value: 1}},
+	}); err != nil { return err }
+
+	// notifications: newest first per user
+	if _, err := d.Collection("notifications").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+	}); err != nil { return err }
+
+	// waitlist FIFO per room; entries are matched by interval containment
+	if _, err := d.Collection("waitlist").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "room_id", Value: 1}, {Key: "start_at", Value: 1}, {Key: "end_at", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "end_at", Value: 1}}},
+	}); err != nil { return err }
+
+	// amenity catalog: unique key; rooms are filtered by the keys they carry
+	if _, err := d.Collection("amenities").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "key", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil { return err }
+	if _, err := d.Collection("rooms").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "amenities", Value: 1}},
+	}); err != nil { return err }
+
+	// search narrows rooms by capacity, then reads the schedule windows of
+	// all candidates in one query
+	if _, err := d.Collection("rooms").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "capacity", Value: 1}},
+	}); err != nil { return err }
+	if _, err := d.Collection("room_schedules").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "room_id", Value: 1}, {Key: "start_at", Value: 1}, {Key: "end_at", Value: 1}},
+	}); err != nil { return err }
+
+	// holiday calendar, looked up by date
+	if _, err := d.Collection("holidays").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "date", Value: 1}},
+	}); err != nil { return err }
+
+	// weekly opening hours, latest template first
+	if _, err := d.Collection("room_hours").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "room_id", Value: 1}, {Key: "effective_from", Value: -1}},
+	}); err != nil { return err }
+
+	// deleting a room pulls it from users' favorites
+	if _, err := d.Collection("users").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "favorite_room_ids", Value: 1}},
 	}); err != nil { return err }
 
 	return nil
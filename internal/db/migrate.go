@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// timeCollections hold start_at/end_at pairs that used to be stored as
+// RFC3339 strings.
+var timeCollections = []string{"bookings", "room_schedules", "waitlist", "booking_series", "booking_groups"}
+
+// maxSkippedLogged bounds how many document IDs are logged per field.
+const maxSkippedLogged = 10
+
+// MigrateTimestamps converts start_at/end_at values still stored as RFC3339
+// strings into BSON dates (UTC), honouring whatever offset the string had.
+// Strings that do not parse as dates are left alone, logged and counted as
+// skipped rather than failing the migration. It only touches string values,
+// so running it again is a no-op.
+func MigrateTimestamps(ctx context.Context, d *mongo.Database) (converted, skipped int64, err error) {
+	for _, coll := range timeCollections {
+		for _, field := range []string{"start_at", "end_at"} {
+			isString := bson.M{field: bson.M{"$type": "string"}}
+			res, err := d.Collection(coll).UpdateMany(ctx, isString,
+				mongo.Pipeline{{{Key: "$set", Value: bson.M{field: bson.M{"$convert": bson.M{
+					"input": "$" + field, "to": "date", "onError": "$" + field,
+				}}}}}},
+			)
+			if err != nil { return converted, skipped, fmt.Errorf("migrate %s.%s: %w", coll, field, err) }
+			converted += res.ModifiedCount
+
+			cur, err := d.Collection(coll).Find(ctx, isString, options.Find().SetProjection(bson.M{"_id": 1}))
+			if err != nil { return converted, skipped, fmt.Errorf("migrate %s.%s: %w", coll, field, err) }
+			var bad []struct{ ID interface{} `bson:"_id"` }
+			if err := cur.All(ctx, &bad); err != nil { return converted, skipped, fmt.Errorf("migrate %s.%s: %w", coll, field, err) }
+			if len(bad) == 0 { continue }
+			skipped += int64(len(bad))
+			var ids []interface{}
+			for i := 0; i < len(bad) && i < maxSkippedLogged; i++ { ids = append(ids, bad[i].ID) }
+			log.Printf("migrate %s.%s: %d values are not valid timestamps and were left as strings, e.g. _id %v", coll, field, len(bad), ids)
+		}
+	}
+	return converted, skipped, nil
+}
@@ -2,8 +2,10 @@ package handler
 
 import (
 	"context"
+	"time"
 
 	pb "studyroom/api/proto"
+	"studyroom/internal/repo"
 	"studyroom/internal/service"
 )
 
@@ -36,7 +38,7 @@ func (h *AdminHandler) CreateRoom(ctx context.Context, req *pb.CreateRoomRequest
 		}, nil
 	}
 
-	roomID, err := h.bookingSvc.CreateRoom(req.Name, int(req.Capacity))
+	roomID, err := h.bookingSvc.CreateRoom(req.Name, int(req.Capacity), req.TimeZone, req.BuildingId, req.FloorId)
 	if err != nil {
 		return &pb.CreateRoomResponse{
 			Success: false,
@@ -73,11 +75,7 @@ func (h *AdminHandler) ListRooms(ctx context.Context, req *pb.ListRoomsRequest)
 
 	pbRooms := make([]*pb.Room, len(rooms))
 	for i, r := range rooms {
-		pbRooms[i] = &pb.Room{
-			Id:       r.ID,
-			Name:     r.Name,
-			Capacity: int32(r.Capacity),
-		}
+		pbRooms[i] = toPBRoom(r)
 	}
 
 	return &pb.ListRoomsResponse{
@@ -101,18 +99,893 @@ func (h *AdminHandler) SetRoomSchedule(ctx context.Context, req *pb.SetRoomSched
 		}, nil
 	}
 
-	err = h.bookingSvc.SetRoomSchedule(req.RoomId, req.Start, req.End, req.IsOpen)
+	res, err := h.bookingSvc.SetRoomSchedule(req.RoomId, req.Start, req.End, req.IsOpen, req.ConflictMode, req.Reason)
 	if err != nil {
-		return &pb.SetRoomScheduleResponse{
+		out := &pb.SetRoomScheduleResponse{
 			Success: false,
 			Error:   err.Error(),
-		}, nil
+		}
+		if res != nil {
+			out.Conflicts = toPBBookings(res.Conflicts)
+			// set when the change was saved but cancelling failed partway
+			out.Cancelled = toPBBookings(res.Cancelled)
+			out.WindowId = res.WindowID
+		}
+		return out, nil
 	}
 
 	return &pb.SetRoomScheduleResponse{
+		Success:   true,
+		WindowId:  res.WindowID,
+		Cancelled: toPBBookings(res.Cancelled),
+	}, nil
+}
+
+func (h *AdminHandler) ListRoomSchedule(ctx context.Context, req *pb.ListRoomScheduleRequest) (*pb.ListRoomScheduleResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.ListRoomScheduleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.ListRoomScheduleResponse{
+			Error: "unauthorized: admin access required",
+		}, nil
+	}
+
+	ws, err := h.bookingSvc.ListRoomSchedule(req.RoomId)
+	if err != nil {
+		return &pb.ListRoomScheduleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	pbWindows := make([]*pb.ScheduleWindow, len(ws))
+	for i, w := range ws {
+		pbWindows[i] = toPBWindow(w)
+	}
+
+	return &pb.ListRoomScheduleResponse{
+		Windows: pbWindows,
+	}, nil
+}
+
+func (h *AdminHandler) GetRoomSchedule(ctx context.Context, req *pb.GetRoomScheduleRequest) (*pb.GetRoomScheduleResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.GetRoomScheduleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.GetRoomScheduleResponse{
+			Error: "unauthorized: admin access required",
+		}, nil
+	}
+
+	w, err := h.bookingSvc.GetRoomSchedule(req.RoomId, req.WindowId)
+	if err != nil {
+		return &pb.GetRoomScheduleResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.GetRoomScheduleResponse{
+		Window: toPBWindow(w),
+	}, nil
+}
+
+func (h *AdminHandler) UpdateRoomSchedule(ctx context.Context, req *pb.UpdateRoomScheduleRequest) (*pb.UpdateRoomScheduleResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.UpdateRoomScheduleResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.UpdateRoomScheduleResponse{
+			Success: false,
+			Error:   "unauthorized: admin access required",
+		}, nil
+	}
+
+	res, err := h.bookingSvc.UpdateRoomSchedule(req.RoomId, req.WindowId, req.Start, req.End)
+	if err != nil {
+		out := &pb.UpdateRoomScheduleResponse{
+			Success: false,
+			Error:   err.Error(),
+		}
+		if res != nil {
+			out.Conflicts = toPBBookings(res.Conflicts)
+		}
+		return out, nil
+	}
+
+	return &pb.UpdateRoomScheduleResponse{
+		Success:  true,
+		Orphaned: toPBBookings(res.Orphaned),
+	}, nil
+}
+
+func (h *AdminHandler) DeleteRoomSchedule(ctx context.Context, req *pb.DeleteRoomScheduleRequest) (*pb.DeleteRoomScheduleResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.DeleteRoomScheduleResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.DeleteRoomScheduleResponse{
+			Success: false,
+			Error:   "unauthorized: admin access required",
+		}, nil
+	}
+
+	res, err := h.bookingSvc.DeleteRoomSchedule(req.RoomId, req.WindowId)
+	if err != nil {
+		return &pb.DeleteRoomScheduleResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.DeleteRoomScheduleResponse{
+		Success:  true,
+		Orphaned: toPBBookings(res.Orphaned),
+	}, nil
+}
+
+func toPBWindow(w repo.ScheduleWindow) *pb.ScheduleWindow {
+	return &pb.ScheduleWindow{
+		Id:     w.ID,
+		RoomId: w.RoomID,
+		Start:  w.Start.Format(time.RFC3339),
+		End:    w.End.Format(time.RFC3339),
+		IsOpen: w.IsOpen,
+	}
+}
+
+func (h *AdminHandler) SetRoomPolicy(ctx context.Context, req *pb.SetRoomPolicyRequest) (*pb.SetRoomPolicyResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.SetRoomPolicyResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.SetRoomPolicyResponse{
+			Success: false,
+			Error:   "unauthorized: admin access required",
+		}, nil
+	}
+
+	var p repo.RoomPolicy
+	if req.Policy != nil {
+		p = repo.RoomPolicy{
+			MinDurationMin:     int(req.Policy.MinDurationMin),
+			MaxDurationMin:     int(req.Policy.MaxDurationMin),
+			MaxAdvanceDays:     int(req.Policy.MaxAdvanceDays),
+			MinNoticeMin:       int(req.Policy.MinNoticeMin),
+			SlotGranularityMin: int(req.Policy.SlotGranularityMin),
+			BufferBeforeMin:    int(req.Policy.BufferBeforeMin),
+			BufferAfterMin:     int(req.Policy.BufferAfterMin),
+			MinOccupancyPct:    int(req.Policy.MinOccupancyPct),
+			WaitlistMode:       req.Policy.WaitlistMode,
+		}
+	}
+
+	err = h.bookingSvc.SetRoomPolicy(req.RoomId, p)
+	if err != nil {
+		return &pb.SetRoomPolicyResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.SetRoomPolicyResponse{
+		Success: true,
+	}, nil
+}
+
+func (h *AdminHandler) AddRoomHours(ctx context.Context, req *pb.AddRoomHoursRequest) (*pb.AddRoomHoursResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.AddRoomHoursResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.AddRoomHoursResponse{
+			Success: false,
+			Error:   "unauthorized: admin access required",
+		}, nil
+	}
+
+	if req.Hours == nil {
+		return &pb.AddRoomHoursResponse{
+			Success: false,
+			Error:   "hours are required",
+		}, nil
+	}
+
+	hours := repo.OpeningHours{
+		EffectiveFrom: req.Hours.EffectiveFrom,
+		EffectiveTo:   req.Hours.EffectiveTo,
+	}
+	for _, rg := range req.Hours.Ranges {
+		hours.Ranges = append(hours.Ranges, repo.HoursRange{
+			Weekday: time.Weekday(rg.Weekday),
+			Open:    rg.Open,
+			Close:   rg.Close,
+		})
+	}
+
+	hoursID, err := h.bookingSvc.AddRoomHours(req.Hours.RoomId, hours)
+	if err != nil {
+		return &pb.AddRoomHoursResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.AddRoomHoursResponse{
+		Success: true,
+		HoursId: hoursID,
+	}, nil
+}
+
+func (h *AdminHandler) ListRoomHours(ctx context.Context, req *pb.ListRoomHoursRequest) (*pb.ListRoomHoursResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.ListRoomHoursResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.ListRoomHoursResponse{
+			Error: "unauthorized: admin access required",
+		}, nil
+	}
+
+	hours, err := h.bookingSvc.ListRoomHours(req.RoomId)
+	if err != nil {
+		return &pb.ListRoomHoursResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	pbHours := make([]*pb.OpeningHours, len(hours))
+	for i, oh := range hours {
+		pbHours[i] = &pb.OpeningHours{
+			Id:            oh.ID,
+			RoomId:        oh.RoomID,
+			EffectiveFrom: oh.EffectiveFrom,
+			EffectiveTo:   oh.EffectiveTo,
+		}
+		for _, rg := range oh.Ranges {
+			pbHours[i].Ranges = append(pbHours[i].Ranges, &pb.HoursRange{
+				Weekday: int32(rg.Weekday),
+				Open:    rg.Open,
+				Close:   rg.Close,
+			})
+		}
+	}
+
+	return &pb.ListRoomHoursResponse{
+		Hours: pbHours,
+	}, nil
+}
+
+func (h *AdminHandler) DeleteRoomHours(ctx context.Context, req *pb.DeleteRoomHoursRequest) (*pb.DeleteRoomHoursResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.DeleteRoomHoursResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.DeleteRoomHoursResponse{
+			Success: false,
+			Error:   "unauthorized: admin access required",
+		}, nil
+	}
+
+	err = h.bookingSvc.DeleteRoomHours(req.RoomId, req.HoursId)
+	if err != nil {
+		return &pb.DeleteRoomHoursResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.DeleteRoomHoursResponse{
 		Success: true,
 	}, nil
 }
 
+func (h *AdminHandler) AddHolidays(ctx context.Context, req *pb.AddHolidaysRequest) (*pb.AddHolidaysResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.AddHolidaysResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.AddHolidaysResponse{
+			Success: false,
+			Error:   "unauthorized: admin access required",
+		}, nil
+	}
+
+	var hs []repo.HolidayRow
+	for _, hol := range req.Holidays {
+		hs = append(hs, repo.HolidayRow{Date: hol.Date, Name: hol.Name, BuildingID: hol.BuildingId})
+	}
+	if req.Ics != "" {
+		parsed, err := service.ParseHolidayCalendar(req.Ics)
+		if err != nil {
+			return &pb.AddHolidaysResponse{
+				Success: false,
+				Error:   err.Error(),
+			}, nil
+		}
+		for i := range parsed {
+			parsed[i].BuildingID = req.BuildingId
+		}
+		hs = append(hs, parsed...)
+	}
+
+	if req.DryRun {
+		affected, err := h.bookingSvc.PreviewHolidays(hs)
+		if err != nil {
+			return &pb.AddHolidaysResponse{
+				Success: false,
+				Error:   err.Error(),
+			}, nil
+		}
+		return &pb.AddHolidaysResponse{
+			Success:  true,
+			Holidays: toPBHolidays(hs),
+			Affected: toPBBookings(affected),
+		}, nil
+	}
+
+	ids, res, err := h.bookingSvc.AddHolidays(hs, req.ConflictMode)
+	if err != nil {
+		out := &pb.AddHolidaysResponse{
+			Success: false,
+			Error:   err.Error(),
+		}
+		if res != nil {
+			out.Conflicts = toPBBookings(res.Conflicts)
+			// set when the change was saved but cancelling failed partway
+			out.Cancelled = toPBBookings(res.Cancelled)
+			out.HolidayIds = ids
+		}
+		return out, nil
+	}
+
+	out := &pb.AddHolidaysResponse{
+		Success:    true,
+		HolidayIds: ids,
+		Holidays:   toPBHolidays(hs),
+	}
+	if res != nil {
+		out.Cancelled = toPBBookings(res.Cancelled)
+	}
+	return out, nil
+}
+
+func (h *AdminHandler) ListHolidays(ctx context.Context, req *pb.ListHolidaysRequest) (*pb.ListHolidaysResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.ListHolidaysResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.ListHolidaysResponse{
+			Error: "unauthorized: admin access required",
+		}, nil
+	}
+
+	hs, err := h.bookingSvc.ListHolidays(req.From, req.To)
+	if err != nil {
+		return &pb.ListHolidaysResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.ListHolidaysResponse{
+		Holidays: toPBHolidays(hs),
+	}, nil
+}
 
+func (h *AdminHandler) DeleteHoliday(ctx context.Context, req *pb.DeleteHolidayRequest) (*pb.DeleteHolidayResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.DeleteHolidayResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
 
+	if !user.IsAdmin {
+		return &pb.DeleteHolidayResponse{
+			Success: false,
+			Error:   "unauthorized: admin access required",
+		}, nil
+	}
+
+	err = h.bookingSvc.DeleteHoliday(req.HolidayId)
+	if err != nil {
+		return &pb.DeleteHolidayResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.DeleteHolidayResponse{
+		Success: true,
+	}, nil
+}
+
+func toPBHolidays(hs []repo.HolidayRow) []*pb.Holiday {
+	out := make([]*pb.Holiday, len(hs))
+	for i, hol := range hs {
+		out[i] = &pb.Holiday{
+			Id:         hol.ID,
+			Date:       hol.Date,
+			Name:       hol.Name,
+			BuildingId: hol.BuildingID,
+		}
+	}
+	return out
+}
+
+func toPBRoom(r repo.RoomRow) *pb.Room {
+	return &pb.Room{
+		Id:       r.ID,
+		Name:     r.Name,
+		Capacity: int32(r.Capacity),
+		Policy: &pb.RoomPolicy{
+			MinDurationMin:     int32(r.Policy.MinDurationMin),
+			MaxDurationMin:     int32(r.Policy.MaxDurationMin),
+			MaxAdvanceDays:     int32(r.Policy.MaxAdvanceDays),
+			MinNoticeMin:       int32(r.Policy.MinNoticeMin),
+			SlotGranularityMin: int32(r.Policy.SlotGranularityMin),
+			BufferBeforeMin:    int32(r.Policy.BufferBeforeMin),
+			BufferAfterMin:     int32(r.Policy.BufferAfterMin),
+			MinOccupancyPct:    int32(r.Policy.MinOccupancyPct),
+			WaitlistMode:       r.Policy.WaitlistMode,
+		},
+		TimeZone:   r.TimeZone,
+		Archived:   r.Archived,
+		Amenities:  r.Amenities,
+		BuildingId: r.BuildingID,
+		FloorId:    r.FloorID,
+	}
+}
+
+func toPBBuilding(b repo.BuildingRow) *pb.Building {
+	out := &pb.Building{
+		Id:        b.ID,
+		CampusId:  b.CampusID,
+		Name:      b.Name,
+		Address:   b.Address,
+		Latitude:  b.Latitude,
+		Longitude: b.Longitude,
+		TimeZone:  b.TimeZone,
+	}
+	for _, rg := range b.Hours {
+		out.Hours = append(out.Hours, &pb.HoursRange{
+			Weekday: int32(rg.Weekday),
+			Open:    rg.Open,
+			Close:   rg.Close,
+		})
+	}
+	return out
+}
+
+func fromPBBuilding(b *pb.Building) repo.BuildingRow {
+	out := repo.BuildingRow{
+		ID:        b.Id,
+		CampusID:  b.CampusId,
+		Name:      b.Name,
+		Address:   b.Address,
+		Latitude:  b.Latitude,
+		Longitude: b.Longitude,
+		TimeZone:  b.TimeZone,
+	}
+	for _, rg := range b.Hours {
+		out.Hours = append(out.Hours, repo.HoursRange{
+			Weekday: time.Weekday(rg.Weekday),
+			Open:    rg.Open,
+			Close:   rg.Close,
+		})
+	}
+	return out
+}
+
+func (h *AdminHandler) UpdateRoom(ctx context.Context, req *pb.UpdateRoomRequest) (*pb.UpdateRoomResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.UpdateRoomResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.UpdateRoomResponse{
+			Success: false,
+			Error:   "unauthorized: admin access required",
+		}, nil
+	}
+
+	u := repo.RoomUpdate{
+		Name:       req.Name,
+		Capacity:   int(req.Capacity),
+		TimeZone:   req.TimeZone,
+		BuildingID: req.BuildingId,
+		FloorID:    req.FloorId,
+	}
+	room, err := h.bookingSvc.UpdateRoom(req.RoomId, u, req.UpdateMask)
+	if err != nil {
+		return &pb.UpdateRoomResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.UpdateRoomResponse{
+		Success: true,
+		Room:    toPBRoom(room),
+	}, nil
+}
+
+func (h *AdminHandler) ArchiveRoom(ctx context.Context, req *pb.ArchiveRoomRequest) (*pb.ArchiveRoomResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.ArchiveRoomResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.ArchiveRoomResponse{
+			Success: false,
+			Error:   "unauthorized: admin access required",
+		}, nil
+	}
+
+	res, err := h.bookingSvc.ArchiveRoom(req.RoomId, req.Mode, req.Reason)
+	if err != nil {
+		out := &pb.ArchiveRoomResponse{
+			Success: false,
+			Error:   err.Error(),
+		}
+		if res != nil {
+			out.Conflicts = toPBBookings(res.Conflicts)
+			// set when the change was saved but cancelling failed partway
+			out.Cancelled = toPBBookings(res.Cancelled)
+		}
+		return out, nil
+	}
+
+	return &pb.ArchiveRoomResponse{
+		Success:   true,
+		Cancelled: toPBBookings(res.Cancelled),
+	}, nil
+}
+
+func (h *AdminHandler) RestoreRoom(ctx context.Context, req *pb.RestoreRoomRequest) (*pb.RestoreRoomResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.RestoreRoomResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.RestoreRoomResponse{
+			Success: false,
+			Error:   "unauthorized: admin access required",
+		}, nil
+	}
+
+	err = h.bookingSvc.RestoreRoom(req.RoomId)
+	if err != nil {
+		return &pb.RestoreRoomResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.RestoreRoomResponse{
+		Success: true,
+	}, nil
+}
+
+func (h *AdminHandler) DeleteRoom(ctx context.Context, req *pb.DeleteRoomRequest) (*pb.DeleteRoomResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.DeleteRoomResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.DeleteRoomResponse{
+			Success: false,
+			Error:   "unauthorized: admin access required",
+		}, nil
+	}
+
+	err = h.bookingSvc.DeleteRoom(req.RoomId)
+	if err != nil {
+		return &pb.DeleteRoomResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.DeleteRoomResponse{
+		Success: true,
+	}, nil
+}
+
+func (h *AdminHandler) CreateAmenity(ctx context.Context, req *pb.CreateAmenityRequest) (*pb.CreateAmenityResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.CreateAmenityResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.CreateAmenityResponse{
+			Success: false,
+			Error:   "unauthorized: admin access required",
+		}, nil
+	}
+
+	if req.Amenity == nil {
+		return &pb.CreateAmenityResponse{
+			Success: false,
+			Error:   "amenity is required",
+		}, nil
+	}
+
+	err = h.bookingSvc.CreateAmenity(req.Amenity.Key, req.Amenity.Name)
+	if err != nil {
+		return &pb.CreateAmenityResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.CreateAmenityResponse{
+		Success: true,
+	}, nil
+}
+
+func (h *AdminHandler) DeleteAmenity(ctx context.Context, req *pb.DeleteAmenityRequest) (*pb.DeleteAmenityResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.DeleteAmenityResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.DeleteAmenityResponse{
+			Success: false,
+			Error:   "unauthorized: admin access required",
+		}, nil
+	}
+
+	err = h.bookingSvc.DeleteAmenity(req.Key)
+	if err != nil {
+		return &pb.DeleteAmenityResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.DeleteAmenityResponse{
+		Success: true,
+	}, nil
+}
+
+func (h *AdminHandler) SetRoomAmenities(ctx context.Context, req *pb.SetRoomAmenitiesRequest) (*pb.SetRoomAmenitiesResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.SetRoomAmenitiesResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.SetRoomAmenitiesResponse{
+			Success: false,
+			Error:   "unauthorized: admin access required",
+		}, nil
+	}
+
+	err = h.bookingSvc.SetRoomAmenities(req.RoomId, req.Amenities)
+	if err != nil {
+		return &pb.SetRoomAmenitiesResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.SetRoomAmenitiesResponse{
+		Success: true,
+	}, nil
+}
+
+func (h *AdminHandler) CreateCampus(ctx context.Context, req *pb.CreateCampusRequest) (*pb.CreateCampusResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.CreateCampusResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.CreateCampusResponse{
+			Success: false,
+			Error:   "unauthorized: admin access required",
+		}, nil
+	}
+
+	campusID, err := h.bookingSvc.CreateCampus(req.Name)
+	if err != nil {
+		return &pb.CreateCampusResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.CreateCampusResponse{
+		Success:  true,
+		CampusId: campusID,
+	}, nil
+}
+
+func (h *AdminHandler) CreateBuilding(ctx context.Context, req *pb.CreateBuildingRequest) (*pb.CreateBuildingResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.CreateBuildingResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.CreateBuildingResponse{
+			Success: false,
+			Error:   "unauthorized: admin access required",
+		}, nil
+	}
+
+	if req.Building == nil {
+		return &pb.CreateBuildingResponse{
+			Success: false,
+			Error:   "building is required",
+		}, nil
+	}
+
+	buildingID, err := h.bookingSvc.CreateBuilding(fromPBBuilding(req.Building))
+	if err != nil {
+		return &pb.CreateBuildingResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.CreateBuildingResponse{
+		Success:    true,
+		BuildingId: buildingID,
+	}, nil
+}
+
+func (h *AdminHandler) UpdateBuilding(ctx context.Context, req *pb.UpdateBuildingRequest) (*pb.UpdateBuildingResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.UpdateBuildingResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.UpdateBuildingResponse{
+			Success: false,
+			Error:   "unauthorized: admin access required",
+		}, nil
+	}
+
+	if req.Building == nil {
+		return &pb.UpdateBuildingResponse{
+			Success: false,
+			Error:   "building is required",
+		}, nil
+	}
+
+	b, err := h.bookingSvc.UpdateBuilding(fromPBBuilding(req.Building))
+	if err != nil {
+		return &pb.UpdateBuildingResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.UpdateBuildingResponse{
+		Success:  true,
+		Building: toPBBuilding(b),
+	}, nil
+}
+
+func (h *AdminHandler) CreateFloor(ctx context.Context, req *pb.CreateFloorRequest) (*pb.CreateFloorResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.CreateFloorResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if !user.IsAdmin {
+		return &pb.CreateFloorResponse{
+			Success: false,
+			Error:   "unauthorized: admin access required",
+		}, nil
+	}
+
+	if req.Floor == nil {
+		return &pb.CreateFloorResponse{
+			Success: false,
+			Error:   "floor is required",
+		}, nil
+	}
+
+	floorID, err := h.bookingSvc.CreateFloor(req.Floor.BuildingId, int(req.Floor.Level), req.Floor.Name)
+	if err != nil {
+		return &pb.CreateFloorResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.CreateFloorResponse{
+		Success: true,
+		FloorId: floorID,
+	}, nil
+}
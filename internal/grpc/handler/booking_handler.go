@@ -3,12 +3,14 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	pb "studyroom/api/proto"
 	"studyroom/internal/models"
+	"studyroom/internal/repo"
 	"studyroom/internal/service"
 	"studyroom/internal/twopc"
 	"google.golang.org/grpc"
@@ -69,12 +71,9 @@ func (h *BookingHandler) CreateBooking(ctx context.Context, req *pb.CreateBookin
 	}
 
 	// Otherwise, use direct booking
-	bookingID, err := h.bookingSvc.CreateBooking(req.RoomId, user.ID, req.Start, req.End)
+	bookingID, err := h.bookingSvc.CreateBooking(req.RoomId, user.ID, req.Start, req.End, bookingDetails(req))
 	if err != nil {
-		return &pb.CreateBookingResponse{
-			Success: false,
-			Error:   err.Error(),
-		}, nil
+		return createBookingFailure(err), nil
 	}
 
 	return &pb.CreateBookingResponse{
@@ -86,11 +85,13 @@ func (h *BookingHandler) CreateBooking(ctx context.Context, req *pb.CreateBookin
 func (h *BookingHandler) createBookingWith2PC(ctx context.Context, req *pb.CreateBookingRequest, userID string) (*pb.CreateBookingResponse, error) {
 	// Prepare operation data
 	opData := map[string]interface{}{
-		"type":    "create_booking",
-		"room_id": req.RoomId,
-		"user_id": userID,
-		"start":   req.Start,
-		"end":     req.End,
+		"type":       "create_booking",
+		"room_id":    req.RoomId,
+		"user_id":    userID,
+		"start":      req.Start,
+		"end":        req.End,
+		"party_size": req.PartySize,
+		"attendees":  req.AttendeeEmails,
 	}
 	opJSON, _ := json.Marshal(opData)
 
@@ -120,12 +121,9 @@ func (h *BookingHandler) createBookingWith2PC(ctx context.Context, req *pb.Creat
 	}
 
 	// After successful 2PC, create booking locally
-	bookingID, err := h.bookingSvc.CreateBooking(req.RoomId, userID, req.Start, req.End)
+	bookingID, err := h.bookingSvc.CreateBooking(req.RoomId, userID, req.Start, req.End, bookingDetails(req))
 	if err != nil {
-		return &pb.CreateBookingResponse{
-			Success: false,
-			Error:   err.Error(),
-		}, nil
+		return createBookingFailure(err), nil
 	}
 
 	return &pb.CreateBookingResponse{
@@ -134,6 +132,26 @@ func (h *BookingHandler) createBookingWith2PC(ctx context.Context, req *pb.Creat
 	}, nil
 }
 
+func (h *BookingHandler) ListBookings(ctx context.Context, req *pb.ListBookingsRequest) (*pb.ListBookingsResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.ListBookingsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	bookings, err := h.bookingSvc.ListBookings(user.ID)
+	if err != nil {
+		return &pb.ListBookingsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.ListBookingsResponse{
+		Bookings: toPBBookings(bookings),
+	}, nil
+}
+
 func (h *BookingHandler) CancelBooking(ctx context.Context, req *pb.CancelBookingRequest) (*pb.CancelBookingResponse, error) {
 	user, err := h.getUserFromToken(req.SessionToken)
 	if err != nil {
@@ -154,6 +172,125 @@ func (h *BookingHandler) CancelBooking(ctx context.Context, req *pb.CancelBookin
 	return &pb.CancelBookingResponse{Success: true}, nil
 }
 
+func (h *BookingHandler) RescheduleBooking(ctx context.Context, req *pb.RescheduleBookingRequest) (*pb.RescheduleBookingResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.RescheduleBookingResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	err = h.bookingSvc.RescheduleBooking(req.BookingId, user.ID, req.Start, req.End)
+	if err != nil {
+		return &pb.RescheduleBookingResponse{
+			Success:     false,
+			Error:       err.Error(),
+			Suggestions: toPBSuggestions(err),
+		}, nil
+	}
+
+	return &pb.RescheduleBookingResponse{Success: true}, nil
+}
+
+func (h *BookingHandler) CheckIn(ctx context.Context, req *pb.CheckInRequest) (*pb.CheckInResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.CheckInResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	err = h.bookingSvc.CheckIn(req.BookingId, user.ID)
+	if err != nil {
+		return &pb.CheckInResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.CheckInResponse{Success: true}, nil
+}
+
+func (h *BookingHandler) HoldSlot(ctx context.Context, req *pb.HoldSlotRequest) (*pb.HoldSlotResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.HoldSlotResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	d := service.BookingDetails{
+		PartySize:      int(req.PartySize),
+		AttendeeEmails: req.AttendeeEmails,
+	}
+	bookingID, exp, err := h.bookingSvc.HoldSlot(req.RoomId, user.ID, req.Start, req.End, d)
+	if err != nil {
+		return &pb.HoldSlotResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.HoldSlotResponse{
+		Success:   true,
+		BookingId: bookingID,
+		ExpiresAt: exp.Format(time.RFC3339),
+	}, nil
+}
+
+func (h *BookingHandler) ConfirmHold(ctx context.Context, req *pb.ConfirmHoldRequest) (*pb.ConfirmHoldResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.ConfirmHoldResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	err = h.bookingSvc.ConfirmHold(req.BookingId, user.ID)
+	if err != nil {
+		return &pb.ConfirmHoldResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.ConfirmHoldResponse{Success: true}, nil
+}
+
+func (h *BookingHandler) ListNotifications(ctx context.Context, req *pb.ListNotificationsRequest) (*pb.ListNotificationsResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.ListNotificationsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	notes, err := h.bookingSvc.ListNotifications(user.ID)
+	if err != nil {
+		return &pb.ListNotificationsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	out := make([]*pb.Notification, len(notes))
+	for i, n := range notes {
+		out[i] = &pb.Notification{
+			Id:        n.ID,
+			Kind:      n.Kind,
+			Message:   n.Message,
+			CreatedAt: n.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	return &pb.ListNotificationsResponse{
+		Notifications: out,
+	}, nil
+}
+
 func (h *BookingHandler) JoinWaitlist(ctx context.Context, req *pb.JoinWaitlistRequest) (*pb.JoinWaitlistResponse, error) {
 	user, err := h.getUserFromToken(req.SessionToken)
 	if err != nil {
@@ -163,7 +300,7 @@ func (h *BookingHandler) JoinWaitlist(ctx context.Context, req *pb.JoinWaitlistR
 		}, nil
 	}
 
-	err = h.bookingSvc.JoinWaitlist(req.RoomId, user.ID, req.Start, req.End)
+	err = h.bookingSvc.JoinWaitlist(req.RoomId, user.ID, req.Start, req.End, int(req.PartySize))
 	if err != nil {
 		return &pb.JoinWaitlistResponse{
 			Success: false,
@@ -174,7 +311,493 @@ func (h *BookingHandler) JoinWaitlist(ctx context.Context, req *pb.JoinWaitlistR
 	return &pb.JoinWaitlistResponse{Success: true}, nil
 }
 
+func (h *BookingHandler) ListWaitlist(ctx context.Context, req *pb.ListWaitlistRequest) (*pb.ListWaitlistResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.ListWaitlistResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	entries, err := h.bookingSvc.ListWaitlist(user.ID)
+	if err != nil {
+		return &pb.ListWaitlistResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	out := make([]*pb.WaitlistEntry, len(entries))
+	for i, e := range entries {
+		out[i] = &pb.WaitlistEntry{
+			Id:          e.ID,
+			RoomId:      e.RoomID,
+			MinCapacity: int32(e.MinCapacity),
+			Amenities:   e.Amenities,
+			BuildingId:  e.BuildingID,
+			FloorId:     e.FloorID,
+			PartySize:   int32(e.PartySize),
+			Start:       e.Start.Format(time.RFC3339),
+			End:         e.End.Format(time.RFC3339),
+			Position:    int32(e.Position),
+			CreatedAt:   e.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	return &pb.ListWaitlistResponse{
+		Entries: out,
+	}, nil
+}
+
+func (h *BookingHandler) JoinAnyRoomWaitlist(ctx context.Context, req *pb.JoinAnyRoomWaitlistRequest) (*pb.JoinAnyRoomWaitlistResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.JoinAnyRoomWaitlistResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	f := repo.RoomFilter{
+		MinCapacity: int(req.MinCapacity),
+		Amenities:   req.Amenities,
+		BuildingID:  req.BuildingId,
+		FloorID:     req.FloorId,
+	}
+	err = h.bookingSvc.JoinAnyRoomWaitlist(user.ID, req.Start, req.End, f, int(req.PartySize))
+	if err != nil {
+		return &pb.JoinAnyRoomWaitlistResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.JoinAnyRoomWaitlistResponse{Success: true}, nil
+}
+
+func (h *BookingHandler) ListOffers(ctx context.Context, req *pb.ListOffersRequest) (*pb.ListOffersResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.ListOffersResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	offers, err := h.bookingSvc.ListOffers(user.ID)
+	if err != nil {
+		return &pb.ListOffersResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	out := make([]*pb.Offer, len(offers))
+	for i, o := range offers {
+		out[i] = &pb.Offer{
+			BookingId: o.ID,
+			RoomId:    o.RoomID,
+			Start:     o.Start.Format(time.RFC3339),
+			End:       o.End.Format(time.RFC3339),
+		}
+		if o.HoldExpiresAt != nil {
+			out[i].ExpiresAt = o.HoldExpiresAt.Format(time.RFC3339)
+		}
+	}
+
+	return &pb.ListOffersResponse{
+		Offers: out,
+	}, nil
+}
+
+func (h *BookingHandler) AcceptOffer(ctx context.Context, req *pb.AcceptOfferRequest) (*pb.AcceptOfferResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.AcceptOfferResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	err = h.bookingSvc.AcceptOffer(req.BookingId, user.ID)
+	if err != nil {
+		return &pb.AcceptOfferResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.AcceptOfferResponse{Success: true}, nil
+}
+
+func (h *BookingHandler) DeclineOffer(ctx context.Context, req *pb.DeclineOfferRequest) (*pb.DeclineOfferResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.DeclineOfferResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	err = h.bookingSvc.DeclineOffer(req.BookingId, user.ID)
+	if err != nil {
+		return &pb.DeclineOfferResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.DeclineOfferResponse{Success: true}, nil
+}
+
+func (h *BookingHandler) LeaveWaitlist(ctx context.Context, req *pb.LeaveWaitlistRequest) (*pb.LeaveWaitlistResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.LeaveWaitlistResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	if req.EntryId != "" {
+		err = h.bookingSvc.LeaveWaitlistEntry(req.EntryId, user.ID)
+	} else {
+		err = h.bookingSvc.LeaveWaitlist(req.RoomId, user.ID, req.Start, req.End)
+	}
+	if err != nil {
+		return &pb.LeaveWaitlistResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.LeaveWaitlistResponse{Success: true}, nil
+}
+
+func (h *BookingHandler) CreateRecurringBooking(ctx context.Context, req *pb.CreateRecurringBookingRequest) (*pb.CreateRecurringBookingResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.CreateRecurringBookingResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	var rule service.RecurrenceRule
+	if r := req.Rule; r != nil {
+		rule = service.RecurrenceRule{
+			Freq:     r.Freq,
+			Interval: int(r.Interval),
+			ByDay:    r.ByDay,
+			Until:    r.Until,
+			Count:    int(r.Count),
+			ExDates:  r.ExDates,
+		}
+	}
+
+	d := service.BookingDetails{
+		PartySize:      int(req.PartySize),
+		AttendeeEmails: req.AttendeeEmails,
+	}
+	res, err := h.bookingSvc.CreateRecurringBooking(req.RoomId, user.ID, req.Start, req.End, d, rule, req.Mode)
+	resp := &pb.CreateRecurringBookingResponse{}
+	if res != nil {
+		resp.SeriesId = res.SeriesID
+		resp.BookingIds = res.BookingIDs
+		for _, c := range res.Conflicts {
+			resp.Conflicts = append(resp.Conflicts, &pb.OccurrenceConflict{
+				Start:  c.Start.Format(time.RFC3339),
+				End:    c.End.Format(time.RFC3339),
+				Reason: c.Reason,
+			})
+		}
+	}
+	if err != nil {
+		resp.Error = err.Error()
+		return resp, nil
+	}
+
+	resp.Success = true
+	return resp, nil
+}
+
+func (h *BookingHandler) GetSeries(ctx context.Context, req *pb.GetSeriesRequest) (*pb.GetSeriesResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.GetSeriesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	ser, occs, err := h.bookingSvc.GetSeries(req.SeriesId, user.ID)
+	if err != nil {
+		return &pb.GetSeriesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.GetSeriesResponse{
+		SeriesId: ser.ID,
+		RoomId:   ser.RoomID,
+		Rule: &pb.RecurrenceRule{
+			Freq:     ser.Freq,
+			Interval: int32(ser.Interval),
+			ByDay:    ser.ByDay,
+			Until:    ser.Until,
+			Count:    int32(ser.Count),
+			ExDates:  ser.ExDates,
+		},
+		Status:   ser.Status,
+		Bookings: toPBBookings(occs),
+	}, nil
+}
+
+func (h *BookingHandler) CancelSeries(ctx context.Context, req *pb.CancelSeriesRequest) (*pb.CancelSeriesResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.CancelSeriesResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	n, err := h.bookingSvc.CancelSeries(req.SeriesId, user.ID, req.FromBookingId)
+	if err != nil {
+		return &pb.CancelSeriesResponse{
+			Success:   false,
+			Cancelled: int32(n),
+			Error:     err.Error(),
+		}, nil
+	}
+
+	return &pb.CancelSeriesResponse{
+		Success:   true,
+		Cancelled: int32(n),
+	}, nil
+}
+
+func (h *BookingHandler) CreateGroupBooking(ctx context.Context, req *pb.CreateGroupBookingRequest) (*pb.CreateGroupBookingResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.CreateGroupBookingResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	spec := service.GroupSpec{
+		RoomIDs:     req.RoomIds,
+		Count:       int(req.Count),
+		MinCapacity: int(req.MinCapacity),
+		Amenities:   req.Amenities,
+		BuildingID:  req.BuildingId,
+	}
+	d := service.BookingDetails{
+		PartySize:      int(req.PartySize),
+		AttendeeEmails: req.AttendeeEmails,
+	}
+	res, err := h.bookingSvc.CreateGroupBooking(user.ID, req.Start, req.End, spec, d)
+	resp := &pb.CreateGroupBookingResponse{}
+	if res != nil {
+		resp.GroupId = res.GroupID
+		resp.BookingIds = res.BookingIDs
+		for _, c := range res.Conflicts {
+			resp.Conflicts = append(resp.Conflicts, &pb.RoomConflict{
+				RoomId: c.RoomID,
+				Reason: c.Reason,
+			})
+		}
+	}
+	if err != nil {
+		resp.Error = err.Error()
+		return resp, nil
+	}
+
+	resp.Success = true
+	return resp, nil
+}
+
+func (h *BookingHandler) GetGroupBooking(ctx context.Context, req *pb.GetGroupBookingRequest) (*pb.GetGroupBookingResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.GetGroupBookingResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	g, bs, err := h.bookingSvc.GetGroup(req.GroupId, user.ID)
+	if err != nil {
+		return &pb.GetGroupBookingResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.GetGroupBookingResponse{
+		GroupId:  g.ID,
+		Start:    g.Start.Format(time.RFC3339),
+		End:      g.End.Format(time.RFC3339),
+		RoomIds:  g.RoomIDs,
+		Status:   g.Status,
+		Bookings: toPBBookings(bs),
+	}, nil
+}
+
+func (h *BookingHandler) CancelGroupBooking(ctx context.Context, req *pb.CancelGroupBookingRequest) (*pb.CancelGroupBookingResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.CancelGroupBookingResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	n, err := h.bookingSvc.CancelGroup(req.GroupId, user.ID)
+	if err != nil {
+		return &pb.CancelGroupBookingResponse{
+			Success:   false,
+			Cancelled: int32(n),
+			Error:     err.Error(),
+		}, nil
+	}
+
+	return &pb.CancelGroupBookingResponse{
+		Success:   true,
+		Cancelled: int32(n),
+	}, nil
+}
+
+func (h *BookingHandler) GetQuota(ctx context.Context, req *pb.GetQuotaRequest) (*pb.GetQuotaResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.GetQuotaResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	q, err := h.bookingSvc.Quota(user.ID)
+	if err != nil {
+		return &pb.GetQuotaResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.GetQuotaResponse{
+		ActiveBookings: toPBQuotaItem(q.ActiveBookings),
+		HoursToday:     toPBQuotaItem(q.HoursToday),
+		HoursThisWeek:  toPBQuotaItem(q.HoursThisWeek),
+		Simultaneous:   toPBQuotaItem(q.Simultaneous),
+	}, nil
+}
+
+func (h *BookingHandler) GetRoomTimeline(ctx context.Context, req *pb.GetRoomTimelineRequest) (*pb.GetRoomTimelineResponse, error) {
+	user, err := h.getUserFromToken(req.SessionToken)
+	if err != nil {
+		return &pb.GetRoomTimelineResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	tls, err := h.bookingSvc.RoomTimeline(user.ID, user.IsAdmin, req.RoomIds, req.From, req.To)
+	if err != nil {
+		return &pb.GetRoomTimelineResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	out := make([]*pb.RoomTimeline, len(tls))
+	for i, tl := range tls {
+		out[i] = &pb.RoomTimeline{
+			RoomId:   tl.RoomID,
+			RoomName: tl.RoomName,
+			TimeZone: tl.TimeZone,
+			Open:     toPBPeriods(tl.Open),
+			Closed:   toPBPeriods(tl.Closed),
+		}
+		for _, b := range tl.Busy {
+			out[i].Busy = append(out[i].Busy, &pb.BusyPeriod{
+				Start:     b.Start.Format(time.RFC3339),
+				End:       b.End.Format(time.RFC3339),
+				Status:    b.Status,
+				Mine:      b.Mine,
+				BookingId: b.BookingID,
+				UserId:    b.UserID,
+			})
+		}
+	}
+
+	return &pb.GetRoomTimelineResponse{
+		Timelines: out,
+	}, nil
+}
+
 // Helper functions
+func bookingDetails(req *pb.CreateBookingRequest) service.BookingDetails {
+	return service.BookingDetails{
+		PartySize:      int(req.PartySize),
+		AttendeeEmails: req.AttendeeEmails,
+	}
+}
+
+// createBookingFailure carries the suggestions of a slot that could not be
+// booked along with the error.
+func createBookingFailure(err error) *pb.CreateBookingResponse {
+	return &pb.CreateBookingResponse{
+		Success:     false,
+		Error:       err.Error(),
+		Suggestions: toPBSuggestions(err),
+	}
+}
+
+// toPBSuggestions returns the suggestions err carries, or nil.
+func toPBSuggestions(err error) *pb.BookingSuggestions {
+	var ue *service.SlotUnavailableError
+	if !errors.As(err, &ue) {
+		return nil
+	}
+	return &pb.BookingSuggestions{
+		SameRoom:   toPBSlots(ue.Suggestions.SameRoom),
+		OtherRooms: toPBSlots(ue.Suggestions.OtherRooms),
+	}
+}
+
+func toPBQuotaItem(q service.QuotaItem) *pb.QuotaItem {
+	return &pb.QuotaItem{
+		Limit:     q.Limit,
+		Used:      q.Used,
+		Remaining: q.Remaining,
+		Unlimited: q.Unlimited,
+	}
+}
+
+func toPBPeriods(ps []repo.Period) []*pb.Period {
+	out := make([]*pb.Period, len(ps))
+	for i, p := range ps {
+		out[i] = &pb.Period{
+			Start:  p.Start.Format(time.RFC3339),
+			End:    p.End.Format(time.RFC3339),
+			Reason: p.Reason,
+		}
+	}
+	return out
+}
+
+func toPBBookings(rows []repo.BookingRow) []*pb.Booking {
+	out := make([]*pb.Booking, len(rows))
+	for i, b := range rows {
+		out[i] = &pb.Booking{
+			Id:           b.ID,
+			RoomId:       b.RoomID,
+			Start:        b.Start.Format(time.RFC3339),
+			End:          b.End.Format(time.RFC3339),
+			Status:       b.Status,
+			SeriesId:     b.SeriesID,
+			GroupId:      b.GroupID,
+			UserId:       b.UserID,
+			PartySize:    int32(b.PartySize),
+			AttendeeIds:  b.Attendees,
+			CancelReason: b.CancelReason,
+		}
+	}
+	return out
+}
+
 func (h *BookingHandler) getUserFromToken(token string) (*models.User, error) {
 	if h.authSvc == nil {
 		return nil, fmt.Errorf("auth service not available")
@@ -2,8 +2,10 @@ package handler
 
 import (
 	"context"
+	"time"
 
 	pb "studyroom/api/proto"
+	"studyroom/internal/repo"
 	"studyroom/internal/service"
 )
 
@@ -22,31 +24,269 @@ func NewSearchHandler(searchSvc service.SearchService, authSvc service.AuthServi
 
 func (h *SearchHandler) SearchRooms(ctx context.Context, req *pb.SearchRoomsRequest) (*pb.SearchRoomsResponse, error) {
 	// Verify session
-	_, err := h.authSvc.CurrentUser(req.SessionToken)
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
 	if err != nil {
 		return &pb.SearchRoomsResponse{
 			Error: err.Error(),
 		}, nil
 	}
 
-	rooms, err := h.searchSvc.FindAvailable(int(req.MinCapacity), req.Start, req.End)
+	f := repo.RoomFilter{
+		MinCapacity: int(req.MinCapacity),
+		Amenities:   req.Amenities,
+		BuildingID:  req.BuildingId,
+		FloorID:     req.FloorId,
+	}
+	page, err := h.searchSvc.Search(service.SearchQuery{
+		Filter:         f,
+		Start:          req.Start,
+		End:            req.End,
+		SortBy:         req.SortBy,
+		NearBuildingID: req.NearBuildingId,
+		UserID:         user.ID,
+		PageSize:       int(req.PageSize),
+		Cursor:         req.Cursor,
+	})
 	if err != nil {
 		return &pb.SearchRoomsResponse{
 			Error: err.Error(),
 		}, nil
 	}
 
+	pbRooms := make([]*pb.Room, len(page.Rooms))
+	for i, r := range page.Rooms {
+		pbRooms[i] = toPBRoom(r)
+	}
+
+	return &pb.SearchRoomsResponse{
+		Rooms:      pbRooms,
+		Total:      int32(page.Total),
+		NextCursor: page.NextCursor,
+	}, nil
+}
+
+
+func (h *SearchHandler) ListAmenities(ctx context.Context, req *pb.ListAmenitiesRequest) (*pb.ListAmenitiesResponse, error) {
+	_, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.ListAmenitiesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	amenities, err := h.searchSvc.ListAmenities()
+	if err != nil {
+		return &pb.ListAmenitiesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	out := make([]*pb.Amenity, len(amenities))
+	for i, a := range amenities {
+		out[i] = &pb.Amenity{
+			Key:  a.Key,
+			Name: a.Name,
+		}
+	}
+
+	return &pb.ListAmenitiesResponse{
+		Amenities: out,
+	}, nil
+}
+
+func (h *SearchHandler) ListCampuses(ctx context.Context, req *pb.ListCampusesRequest) (*pb.ListCampusesResponse, error) {
+	_, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.ListCampusesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	campuses, err := h.searchSvc.ListCampuses()
+	if err != nil {
+		return &pb.ListCampusesResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	out := make([]*pb.Campus, len(campuses))
+	for i, c := range campuses {
+		out[i] = &pb.Campus{
+			Id:   c.ID,
+			Name: c.Name,
+		}
+	}
+
+	return &pb.ListCampusesResponse{
+		Campuses: out,
+	}, nil
+}
+
+func (h *SearchHandler) ListBuildings(ctx context.Context, req *pb.ListBuildingsRequest) (*pb.ListBuildingsResponse, error) {
+	_, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.ListBuildingsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	buildings, err := h.searchSvc.ListBuildings(req.CampusId)
+	if err != nil {
+		return &pb.ListBuildingsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	out := make([]*pb.Building, len(buildings))
+	for i, b := range buildings {
+		out[i] = toPBBuilding(b)
+	}
+
+	return &pb.ListBuildingsResponse{
+		Buildings: out,
+	}, nil
+}
+
+func (h *SearchHandler) GetBuilding(ctx context.Context, req *pb.GetBuildingRequest) (*pb.GetBuildingResponse, error) {
+	_, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.GetBuildingResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	b, err := h.searchSvc.GetBuilding(req.BuildingId)
+	if err != nil {
+		return &pb.GetBuildingResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.GetBuildingResponse{
+		Building: toPBBuilding(b),
+	}, nil
+}
+
+func (h *SearchHandler) ListFloors(ctx context.Context, req *pb.ListFloorsRequest) (*pb.ListFloorsResponse, error) {
+	_, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.ListFloorsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	floors, err := h.searchSvc.ListFloors(req.BuildingId)
+	if err != nil {
+		return &pb.ListFloorsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	out := make([]*pb.Floor, len(floors))
+	for i, fl := range floors {
+		out[i] = &pb.Floor{
+			Id:         fl.ID,
+			BuildingId: fl.BuildingID,
+			Level:      int32(fl.Level),
+			Name:       fl.Name,
+		}
+	}
+
+	return &pb.ListFloorsResponse{
+		Floors: out,
+	}, nil
+}
+
+func (h *SearchHandler) FindNextSlots(ctx context.Context, req *pb.FindNextSlotsRequest) (*pb.FindNextSlotsResponse, error) {
+	_, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.FindNextSlotsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	q := service.SlotQuery{
+		Filter: repo.RoomFilter{
+			MinCapacity: int(req.MinCapacity),
+			Amenities:   req.Amenities,
+			BuildingID:  req.BuildingId,
+			FloorID:     req.FloorId,
+		},
+		Duration:   time.Duration(req.DurationMin) * time.Minute,
+		From:       req.From,
+		Horizon:    time.Duration(req.HorizonDays) * 24 * time.Hour,
+		PreferFrom: req.PreferFrom,
+		PreferTo:   req.PreferTo,
+		Limit:      int(req.Limit),
+	}
+	slots, err := h.searchSvc.FindNextSlots(q)
+	if err != nil {
+		return &pb.FindNextSlotsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &pb.FindNextSlotsResponse{
+		Slots: toPBSlots(slots),
+	}, nil
+}
+
+func toPBSlots(slots []service.Slot) []*pb.Slot {
+	out := make([]*pb.Slot, len(slots))
+	for i, sl := range slots {
+		out[i] = &pb.Slot{
+			RoomId:   sl.RoomID,
+			RoomName: sl.RoomName,
+			Start:    sl.Start.Format(time.RFC3339),
+			End:      sl.End.Format(time.RFC3339),
+		}
+	}
+	return out
+}
+
+func (h *SearchHandler) ListFavoriteRooms(ctx context.Context, req *pb.ListFavoriteRoomsRequest) (*pb.ListFavoriteRoomsResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.ListFavoriteRoomsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	rooms, err := h.searchSvc.ListFavorites(user.ID)
+	if err != nil {
+		return &pb.ListFavoriteRoomsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
 	pbRooms := make([]*pb.Room, len(rooms))
 	for i, r := range rooms {
-		pbRooms[i] = &pb.Room{
-			Id:       r.ID,
-			Name:     r.Name,
-			Capacity: int32(r.Capacity),
-		}
+		pbRooms[i] = toPBRoom(r)
 	}
 
-	return &pb.SearchRoomsResponse{
+	return &pb.ListFavoriteRoomsResponse{
 		Rooms: pbRooms,
 	}, nil
 }
 
+func (h *SearchHandler) SetFavoriteRoom(ctx context.Context, req *pb.SetFavoriteRoomRequest) (*pb.SetFavoriteRoomResponse, error) {
+	user, err := h.authSvc.CurrentUser(req.SessionToken)
+	if err != nil {
+		return &pb.SetFavoriteRoomResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	err = h.searchSvc.SetFavorite(user.ID, req.RoomId, req.Favorite)
+	if err != nil {
+		return &pb.SetFavoriteRoomResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.SetFavoriteRoomResponse{
+		Success: true,
+	}, nil
+}
@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"studyroom/internal/repo"
 	"studyroom/internal/service"
 )
 
@@ -12,13 +16,89 @@ type AdminHandler struct{ svc service.BookingService }
 func NewAdminHandler(s service.BookingService) *AdminHandler { return &AdminHandler{svc: s} }
 
 type roomIn struct {
-	Name     string `json:"name" binding:"required"`
-	Capacity int    `json:"capacity" binding:"required"`
+	Name       string `json:"name" binding:"required"`
+	Capacity   int    `json:"capacity" binding:"required"`
+	TimeZone   string `json:"time_zone"` // IANA name; defaults to the building's, else UTC
+	BuildingID string `json:"building_id"`
+	FloorID    string `json:"floor_id"` // needs building_id
 }
+// roomPatchIn updates the fields listed in update_mask, or when it is
+// absent, the fields present in the body.
+type roomPatchIn struct {
+	Name       *string  `json:"name"`
+	Capacity   *int     `json:"capacity"`
+	TimeZone   *string  `json:"time_zone"`
+	BuildingID *string  `json:"building_id"` // "" takes the room out of its building
+	FloorID    *string  `json:"floor_id"`
+	UpdateMask []string `json:"update_mask"`
+}
+
+type campusIn struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type buildingIn struct {
+	CampusID  string            `json:"campus_id"` // on create only
+	Name      string            `json:"name" binding:"required"`
+	Address   string            `json:"address"`
+	Latitude  float64           `json:"latitude"`
+	Longitude float64           `json:"longitude"`
+	Hours     []repo.HoursRange `json:"hours"` // default opening hours of its rooms
+	TimeZone  string            `json:"time_zone"` // IANA name for rooms without a zone of their own
+}
+
+type floorIn struct {
+	Level int    `json:"level"`
+	Name  string `json:"name"`
+}
+
+type archiveIn struct {
+	Mode   string `json:"mode"`   // reject (default) | cancel | keep
+	Reason string `json:"reason"` // passed on to owners of cancelled bookings
+}
+
+type amenityIn struct {
+	Key  string `json:"key" binding:"required"`
+	Name string `json:"name" binding:"required"`
+}
+
+type roomAmenitiesIn struct {
+	Amenities []string `json:"amenities"`
+}
+
 type scheduleIn struct {
 	Start  string `json:"start" binding:"required"`
 	End    string `json:"end" binding:"required"`
 	IsOpen bool   `json:"is_open"`
+
+	// closures only: what to do with bookings in the window
+	ConflictMode string `json:"conflict_mode"` // reject (default) | cancel
+	Reason       string `json:"reason"`        // passed on to owners of cancelled bookings
+}
+
+type hoursIn struct {
+	EffectiveFrom string            `json:"effective_from" binding:"required"`
+	EffectiveTo   string            `json:"effective_to"`
+	Ranges        []repo.HoursRange `json:"ranges" binding:"required"`
+}
+
+type holidaysIn struct {
+	Holidays     []repo.HolidayRow `json:"holidays" binding:"required"`
+	ConflictMode string            `json:"conflict_mode"` // reject (default) | cancel
+	DryRun       bool              `json:"dry_run"`       // only list the bookings that would be affected
+}
+
+type policyIn struct {
+	MinDurationMin     int `json:"min_duration_min"`
+	MaxDurationMin     int `json:"max_duration_min"`
+	MaxAdvanceDays     int `json:"max_advance_days"`
+	MinNoticeMin       int `json:"min_notice_min"`
+	SlotGranularityMin int `json:"slot_granularity_min"`
+	BufferBeforeMin    int `json:"buffer_before_min"`
+	BufferAfterMin     int `json:"buffer_after_min"`
+	MinOccupancyPct    int `json:"min_occupancy_pct"`
+
+	WaitlistMode string `json:"waitlist_mode"` // offer | auto
 }
 
 func (h *AdminHandler) CreateRoom(c *gin.Context) {
@@ -26,7 +106,7 @@ func (h *AdminHandler) CreateRoom(c *gin.Context) {
 	if err := c.ShouldBindJSON(&in); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error":"invalid JSON"}); return
 	}
-	id, err := h.svc.CreateRoom(in.Name, in.Capacity)
+	id, err := h.svc.CreateRoom(in.Name, in.Capacity, in.TimeZone, in.BuildingID, in.FloorID)
 	if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
 	c.JSON(http.StatusCreated, gin.H{"id": id})
 }
@@ -37,6 +117,128 @@ func (h *AdminHandler) ListRooms(c *gin.Context) {
 	c.JSON(http.StatusOK, rs)
 }
 
+func (h *AdminHandler) UpdateRoom(c *gin.Context) {
+	var in roomPatchIn
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error":"invalid JSON"}); return
+	}
+	var u repo.RoomUpdate
+	var present []string
+	if in.Name != nil { u.Name, present = *in.Name, append(present, "name") }
+	if in.Capacity != nil { u.Capacity, present = *in.Capacity, append(present, "capacity") }
+	if in.TimeZone != nil { u.TimeZone, present = *in.TimeZone, append(present, "time_zone") }
+	if in.BuildingID != nil { u.BuildingID, present = *in.BuildingID, append(present, "building_id") }
+	if in.FloorID != nil { u.FloorID, present = *in.FloorID, append(present, "floor_id") }
+	mask := in.UpdateMask
+	if mask == nil { mask = present }
+	room, err := h.svc.UpdateRoom(c.Param("id"), u, mask)
+	if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+	c.JSON(http.StatusOK, room)
+}
+
+func (h *AdminHandler) ArchiveRoom(c *gin.Context) {
+	var in archiveIn
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&in); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error":"invalid JSON"}); return
+		}
+	}
+	res, err := h.svc.ArchiveRoom(c.Param("id"), in.Mode, in.Reason)
+	if err != nil {
+		if res != nil { closureFailed(c, err, res, gin.H{"status": "archived"}); return }
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "archived", "cancelled": res.Cancelled})
+}
+
+func (h *AdminHandler) RestoreRoom(c *gin.Context) {
+	if err := h.svc.RestoreRoom(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "restored"})
+}
+
+func (h *AdminHandler) DeleteRoom(c *gin.Context) {
+	if err := h.svc.DeleteRoom(c.Param("id")); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+func (h *AdminHandler) CreateCampus(c *gin.Context) {
+	var in campusIn
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error":"invalid JSON"}); return
+	}
+	id, err := h.svc.CreateCampus(in.Name)
+	if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *AdminHandler) CreateBuilding(c *gin.Context) {
+	var in buildingIn
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error":"invalid JSON"}); return
+	}
+	id, err := h.svc.CreateBuilding(in.row(""))
+	if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *AdminHandler) UpdateBuilding(c *gin.Context) {
+	var in buildingIn
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error":"invalid JSON"}); return
+	}
+	b, err := h.svc.UpdateBuilding(in.row(c.Param("id")))
+	if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+	c.JSON(http.StatusOK, b)
+}
+
+func (in buildingIn) row(id string) repo.BuildingRow {
+	return repo.BuildingRow{ID: id, CampusID: in.CampusID, Name: in.Name, Address: in.Address,
+		Latitude: in.Latitude, Longitude: in.Longitude, Hours: in.Hours, TimeZone: in.TimeZone}
+}
+
+func (h *AdminHandler) CreateFloor(c *gin.Context) {
+	var in floorIn
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error":"invalid JSON"}); return
+	}
+	id, err := h.svc.CreateFloor(c.Param("id"), in.Level, in.Name)
+	if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *AdminHandler) CreateAmenity(c *gin.Context) {
+	var in amenityIn
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error":"invalid JSON"}); return
+	}
+	if err := h.svc.CreateAmenity(in.Key, in.Name); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusCreated, gin.H{"key": in.Key})
+}
+
+func (h *AdminHandler) DeleteAmenity(c *gin.Context) {
+	if err := h.svc.DeleteAmenity(c.Param("key")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+func (h *AdminHandler) SetRoomAmenities(c *gin.Context) {
+	var in roomAmenitiesIn
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error":"invalid JSON"}); return
+	}
+	if err := h.svc.SetRoomAmenities(c.Param("id"), in.Amenities); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
 func (h *AdminHandler) SetRoomSchedule(c *gin.Context) {
 	var in scheduleIn
 	if err := c.ShouldBindJSON(&in); err != nil {
@@ -44,7 +246,162 @@ func (h *AdminHandler) SetRoomSchedule(c *gin.Context) {
 	}
 	roomID := c.Param("id") // hex
 	if roomID == "" { c.JSON(http.StatusBadRequest, gin.H{"error":"bad id"}); return }
-	if err := h.svc.SetRoomSchedule(roomID, in.Start, in.End, in.IsOpen); err != nil {
+	res, err := h.svc.SetRoomSchedule(roomID, in.Start, in.End, in.IsOpen, in.ConflictMode, in.Reason)
+	if err != nil {
+		if res != nil { closureFailed(c, err, res, gin.H{"id": res.WindowID}); return }
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "id": res.WindowID, "cancelled": res.Cancelled})
+}
+
+func (h *AdminHandler) ListRoomSchedule(c *gin.Context) {
+	ws, err := h.svc.ListRoomSchedule(c.Param("id"))
+	if err != nil { c.JSON(http.StatusNotFound, gin.H{"error": err.Error()}); return }
+	c.JSON(http.StatusOK, ws)
+}
+
+func (h *AdminHandler) GetRoomSchedule(c *gin.Context) {
+	w, err := h.svc.GetRoomSchedule(c.Param("id"), c.Param("window_id"))
+	if err != nil { c.JSON(http.StatusNotFound, gin.H{"error": err.Error()}); return }
+	c.JSON(http.StatusOK, w)
+}
+
+type windowIn struct {
+	Start string `json:"start" binding:"required"`
+	End   string `json:"end" binding:"required"`
+}
+
+// UpdateRoomSchedule moves a window; bookings an open window no longer
+// covers come back under "orphaned" as a warning.
+func (h *AdminHandler) UpdateRoomSchedule(c *gin.Context) {
+	var in windowIn
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error":"invalid JSON"}); return
+	}
+	res, err := h.svc.UpdateRoomSchedule(c.Param("id"), c.Param("window_id"), in.Start, in.End)
+	if err != nil {
+		if res != nil { closureFailed(c, err, res, gin.H{"id": res.WindowID}); return }
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "orphaned": res.Orphaned})
+}
+
+func (h *AdminHandler) DeleteRoomSchedule(c *gin.Context) {
+	res, err := h.svc.DeleteRoomSchedule(c.Param("id"), c.Param("window_id"))
+	if err != nil { c.JSON(http.StatusNotFound, gin.H{"error": err.Error()}); return }
+	c.JSON(http.StatusOK, gin.H{"status": "deleted", "orphaned": res.Orphaned})
+}
+
+func (h *AdminHandler) AddRoomHours(c *gin.Context) {
+	var in hoursIn
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error":"invalid JSON"}); return
+	}
+	id, err := h.svc.AddRoomHours(c.Param("id"), repo.OpeningHours{EffectiveFrom: in.EffectiveFrom, EffectiveTo: in.EffectiveTo, Ranges: in.Ranges})
+	if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+func (h *AdminHandler) ListRoomHours(c *gin.Context) {
+	hs, err := h.svc.ListRoomHours(c.Param("id"))
+	if err != nil { c.JSON(http.StatusNotFound, gin.H{"error": err.Error()}); return }
+	c.JSON(http.StatusOK, hs)
+}
+
+func (h *AdminHandler) DeleteRoomHours(c *gin.Context) {
+	if err := h.svc.DeleteRoomHours(c.Param("id"), c.Param("hours_id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+func (h *AdminHandler) AddHolidays(c *gin.Context) {
+	var in holidaysIn
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error":"invalid JSON"}); return
+	}
+	h.saveHolidays(c, in.Holidays, in.ConflictMode, in.DryRun)
+}
+
+// maxCalendarBytes bounds an imported iCalendar file.
+const maxCalendarBytes = 1 << 20
+
+// ImportHolidays takes an iCalendar file as the request body; conflict_mode,
+// dry_run and building_id (to close only that building) come from the query
+// string.
+func (h *AdminHandler) ImportHolidays(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxCalendarBytes))
+	var tooBig *http.MaxBytesError
+	if errors.As(err, &tooBig) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("calendar larger than %d bytes", maxCalendarBytes)}); return
+	}
+	if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": "unreadable body"}); return }
+	hs, err := service.ParseHolidayCalendar(string(body))
+	if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+	for i := range hs { hs[i].BuildingID = c.Query("building_id") }
+	h.saveHolidays(c, hs, c.Query("conflict_mode"), c.Query("dry_run") == "true")
+}
+
+func (h *AdminHandler) saveHolidays(c *gin.Context, hs []repo.HolidayRow, mode string, dryRun bool) {
+	if dryRun {
+		affected, err := h.svc.PreviewHolidays(hs)
+		if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+		c.JSON(http.StatusOK, gin.H{"holidays": hs, "affected": affected})
+		return
+	}
+	ids, res, err := h.svc.AddHolidays(hs, mode)
+	if err != nil {
+		if res == nil && len(ids) > 0 { res = &service.ScheduleResult{} }
+		if res != nil { closureFailed(c, err, res, gin.H{"ids": ids}); return }
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return
+	}
+	out := gin.H{"ids": ids}
+	if res != nil { out["cancelled"] = res.Cancelled }
+	c.JSON(http.StatusCreated, out)
+}
+
+// closureFailed answers a closure, archive or holiday change that failed
+// with res. Conflicts mean nothing was saved (409). Otherwise the change
+// itself was saved and failed later, e.g. while cancelling bookings, so the
+// body carries saved plus the bookings already cancelled (500).
+func closureFailed(c *gin.Context, err error, res *service.ScheduleResult, saved gin.H) {
+	if len(res.Conflicts) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "conflicts": res.Conflicts}); return
+	}
+	cancelled := res.Cancelled
+	if cancelled == nil { cancelled = []repo.BookingRow{} }
+	saved["error"], saved["cancelled"] = err.Error(), cancelled
+	c.JSON(http.StatusInternalServerError, saved)
+}
+
+func (h *AdminHandler) ListHolidays(c *gin.Context) {
+	hs, err := h.svc.ListHolidays(c.Query("from"), c.Query("to"))
+	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error":"db error"}); return }
+	c.JSON(http.StatusOK, hs)
+}
+
+func (h *AdminHandler) DeleteHoliday(c *gin.Context) {
+	if err := h.svc.DeleteHoliday(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+func (h *AdminHandler) SetRoomPolicy(c *gin.Context) {
+	var in policyIn
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error":"invalid JSON"}); return
+	}
+	roomID := c.Param("id") // hex
+	if roomID == "" { c.JSON(http.StatusBadRequest, gin.H{"error":"bad id"}); return }
+	p := repo.RoomPolicy{
+		MinDurationMin: in.MinDurationMin, MaxDurationMin: in.MaxDurationMin,
+		MaxAdvanceDays: in.MaxAdvanceDays, MinNoticeMin: in.MinNoticeMin,
+		SlotGranularityMin: in.SlotGranularityMin,
+		BufferBeforeMin: in.BufferBeforeMin, BufferAfterMin: in.BufferAfterMin,
+		MinOccupancyPct: in.MinOccupancyPct, WaitlistMode: in.WaitlistMode,
+	}
+	if err := h.svc.SetRoomPolicy(roomID, p); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return
 	}
 	c.JSON(http.StatusOK, gin.H{"status":"ok"})
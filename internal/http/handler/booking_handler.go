@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"studyroom/internal/models"
+	"studyroom/internal/repo"
 	"studyroom/internal/service"
 )
 
@@ -17,12 +20,66 @@ type bookingIn struct {
 	RoomID string `json:"room_id" binding:"required"` // hex
 	Start  string `json:"start" binding:"required"`   // RFC3339
 	End    string `json:"end" binding:"required"`
+
+	PartySize int      `json:"party_size"` // defaults to owner + attendees
+	Attendees []string `json:"attendees"`  // emails of other registered users
+}
+
+type rescheduleIn struct {
+	Start string `json:"start" binding:"required"` // RFC3339
+	End   string `json:"end" binding:"required"`
+}
+
+type recurrenceIn struct {
+	Freq     string   `json:"freq" binding:"required"` // daily | weekly
+	Interval int      `json:"interval"`
+	ByDay    []string `json:"by_day"`   // MO..SU
+	Until    string   `json:"until"`    // YYYY-MM-DD or RFC3339
+	Count    int      `json:"count"`
+	ExDates  []string `json:"ex_dates"` // YYYY-MM-DD
+}
+
+type seriesIn struct {
+	RoomID string       `json:"room_id" binding:"required"`
+	Start  string       `json:"start" binding:"required"` // first occurrence, RFC3339
+	End    string       `json:"end" binding:"required"`
+	Rule   recurrenceIn `json:"rule" binding:"required"`
+	Mode   string       `json:"mode"` // all_or_nothing (default) | best_effort
+
+	PartySize int      `json:"party_size"`
+	Attendees []string `json:"attendees"`
+}
+
+// groupIn books either the listed rooms or any count rooms seating at
+// least min_capacity and having all amenities, optionally in one building.
+type groupIn struct {
+	Start       string   `json:"start" binding:"required"` // RFC3339
+	End         string   `json:"end" binding:"required"`
+	RoomIDs     []string `json:"room_ids"`
+	Count       int      `json:"count"`
+	MinCapacity int      `json:"min_capacity"`
+	Amenities   []string `json:"amenities"`
+	BuildingID  string   `json:"building_id"`
+	PartySize   int      `json:"party_size"` // per room
+	Attendees   []string `json:"attendees"`
 }
 
 type waitIn struct {
-	RoomID string `json:"room_id" binding:"required"`
-	Start  string `json:"start" binding:"required"`
-	End    string `json:"end" binding:"required"`
+	RoomID    string `json:"room_id" binding:"required"`
+	Start     string `json:"start" binding:"required"`
+	End       string `json:"end" binding:"required"`
+	PartySize int    `json:"party_size"` // 0 means just the caller
+}
+
+// anyRoomWaitIn waits for any room matching the same filters as search.
+type anyRoomWaitIn struct {
+	Start       string   `json:"start" binding:"required"` // RFC3339
+	End         string   `json:"end" binding:"required"`
+	MinCapacity int      `json:"min_capacity"`
+	Amenities   []string `json:"amenities"`
+	BuildingID  string   `json:"building_id"`
+	FloorID     string   `json:"floor_id"` // needs building_id
+	PartySize   int      `json:"party_size"` // defaults to min_capacity
 }
 
 func (h *BookingHandler) Create(c *gin.Context) {
@@ -31,11 +88,56 @@ func (h *BookingHandler) Create(c *gin.Context) {
 	if err := c.ShouldBindJSON(&in); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"}); return
 	}
-	id, err := h.svc.CreateBooking(in.RoomID, u.ID, in.Start, in.End)
-	if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+	d := service.BookingDetails{PartySize: in.PartySize, AttendeeEmails: in.Attendees}
+	id, err := h.svc.CreateBooking(in.RoomID, u.ID, in.Start, in.End, d)
+	var ue *service.SlotUnavailableError
+	if errors.As(err, &ue) {
+		c.JSON(bookingErrStatus(err), gin.H{"error": err.Error(), "suggestions": ue.Suggestions}); return
+	}
+	if err != nil { c.JSON(bookingErrStatus(err), gin.H{"error": err.Error()}); return }
 	c.JSON(http.StatusCreated, gin.H{"booking_id": id})
 }
 
+// Hold reserves a slot for a short time; it must be confirmed before it expires.
+func (h *BookingHandler) Hold(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	var in bookingIn
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"}); return
+	}
+	d := service.BookingDetails{PartySize: in.PartySize, AttendeeEmails: in.Attendees}
+	id, exp, err := h.svc.HoldSlot(in.RoomID, u.ID, in.Start, in.End, d)
+	if err != nil { c.JSON(bookingErrStatus(err), gin.H{"error": err.Error()}); return }
+	c.JSON(http.StatusCreated, gin.H{"booking_id": id, "expires_at": exp.Format(time.RFC3339)})
+}
+
+func (h *BookingHandler) ConfirmHold(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	bid := c.Param("id") // hex booking id
+	if bid == "" { c.JSON(http.StatusBadRequest, gin.H{"error":"bad id"}); return }
+	if err := h.svc.ConfirmHold(bid, u.ID); err != nil {
+		c.JSON(bookingErrStatus(err), gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "confirmed"})
+}
+
+func (h *BookingHandler) Notifications(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	ns, err := h.svc.ListNotifications(u.ID)
+	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"}); return }
+	if ns == nil { ns = []repo.NotificationRow{} }
+	c.JSON(http.StatusOK, ns)
+}
+
+// List returns the caller's upcoming bookings, including those they attend.
+func (h *BookingHandler) List(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	bs, err := h.svc.ListBookings(u.ID)
+	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"}); return }
+	if bs == nil { bs = []repo.BookingRow{} }
+	c.JSON(http.StatusOK, bs)
+}
+
 func (h *BookingHandler) Cancel(c *gin.Context) {
 	u := c.MustGet("user").(*models.User)
 	bid := c.Param("id") // hex booking id
@@ -46,14 +148,210 @@ func (h *BookingHandler) Cancel(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "cancelled"})
 }
 
+func (h *BookingHandler) Reschedule(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	bid := c.Param("id") // hex booking id
+	if bid == "" { c.JSON(http.StatusBadRequest, gin.H{"error":"bad id"}); return }
+	var in rescheduleIn
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"}); return
+	}
+	err := h.svc.RescheduleBooking(bid, u.ID, in.Start, in.End)
+	var ue *service.SlotUnavailableError
+	if errors.As(err, &ue) {
+		c.JSON(bookingErrStatus(err), gin.H{"error": err.Error(), "suggestions": ue.Suggestions}); return
+	}
+	if err != nil { c.JSON(bookingErrStatus(err), gin.H{"error": err.Error()}); return }
+	c.JSON(http.StatusOK, gin.H{"status": "rescheduled"})
+}
+
+func (h *BookingHandler) CheckIn(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	bid := c.Param("id") // hex booking id
+	if bid == "" { c.JSON(http.StatusBadRequest, gin.H{"error":"bad id"}); return }
+	if err := h.svc.CheckIn(bid, u.ID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "checked_in"})
+}
+
 func (h *BookingHandler) JoinWaitlist(c *gin.Context) {
 	u := c.MustGet("user").(*models.User)
 	var in waitIn
 	if err := c.ShouldBindJSON(&in); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"}); return
 	}
-	if err := h.svc.JoinWaitlist(in.RoomID, u.ID, in.Start, in.End); err != nil {
+	if err := h.svc.JoinWaitlist(in.RoomID, u.ID, in.Start, in.End, in.PartySize); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return
 	}
 	c.JSON(http.StatusOK, gin.H{"status": "queued"})
 }
+
+func (h *BookingHandler) JoinAnyRoomWaitlist(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	var in anyRoomWaitIn
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"}); return
+	}
+	f := repo.RoomFilter{MinCapacity: in.MinCapacity, Amenities: in.Amenities, BuildingID: in.BuildingID, FloorID: in.FloorID}
+	if err := h.svc.JoinAnyRoomWaitlist(u.ID, in.Start, in.End, f, in.PartySize); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "queued"})
+}
+
+// LeaveWaitlistEntry removes one of the caller's entries by ID.
+func (h *BookingHandler) LeaveWaitlistEntry(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	if err := h.svc.LeaveWaitlistEntry(c.Param("id"), u.ID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "left"})
+}
+
+// ListWaitlist shows the caller's waitlist entries with their queue position.
+func (h *BookingHandler) ListWaitlist(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	ws, err := h.svc.ListWaitlist(u.ID)
+	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"}); return }
+	if ws == nil { ws = []service.WaitlistEntry{} }
+	c.JSON(http.StatusOK, ws)
+}
+
+// ListOffers shows the freed slots offered to the caller from the waitlist.
+func (h *BookingHandler) ListOffers(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	bs, err := h.svc.ListOffers(u.ID)
+	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"}); return }
+	if bs == nil { bs = []repo.BookingRow{} }
+	c.JSON(http.StatusOK, bs)
+}
+
+func (h *BookingHandler) AcceptOffer(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	if err := h.svc.AcceptOffer(c.Param("id"), u.ID); err != nil {
+		c.JSON(bookingErrStatus(err), gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "confirmed"})
+}
+
+func (h *BookingHandler) DeclineOffer(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	if err := h.svc.DeclineOffer(c.Param("id"), u.ID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "declined"})
+}
+
+// LeaveWaitlist removes the caller's entry given by ?room_id=&start=&end=.
+func (h *BookingHandler) LeaveWaitlist(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	roomID, start, end := c.Query("room_id"), c.Query("start"), c.Query("end")
+	if roomID == "" || start == "" || end == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "room_id, start and end are required"}); return
+	}
+	if err := h.svc.LeaveWaitlist(roomID, u.ID, start, end); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "left"})
+}
+
+func (h *BookingHandler) CreateSeries(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	var in seriesIn
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"}); return
+	}
+	rule := service.RecurrenceRule{
+		Freq: in.Rule.Freq, Interval: in.Rule.Interval, ByDay: in.Rule.ByDay,
+		Until: in.Rule.Until, Count: in.Rule.Count, ExDates: in.Rule.ExDates,
+	}
+	d := service.BookingDetails{PartySize: in.PartySize, AttendeeEmails: in.Attendees}
+	res, err := h.svc.CreateRecurringBooking(in.RoomID, u.ID, in.Start, in.End, d, rule, in.Mode)
+	if err != nil {
+		if res != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "conflicts": res.Conflicts}); return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusCreated, res)
+}
+
+func (h *BookingHandler) GetSeries(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	ser, occs, err := h.svc.GetSeries(c.Param("id"), u.ID)
+	if err != nil { c.JSON(http.StatusNotFound, gin.H{"error": err.Error()}); return }
+	c.JSON(http.StatusOK, gin.H{"series": ser, "bookings": occs})
+}
+
+// CancelSeries cancels the whole series, or with ?from_booking=<id> that
+// occurrence and every later one.
+func (h *BookingHandler) CancelSeries(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	n, err := h.svc.CancelSeries(c.Param("id"), u.ID, c.Query("from_booking"))
+	if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+	c.JSON(http.StatusOK, gin.H{"status": "cancelled", "cancelled": n})
+}
+
+func (h *BookingHandler) CreateGroup(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	var in groupIn
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"}); return
+	}
+	spec := service.GroupSpec{RoomIDs: in.RoomIDs, Count: in.Count, MinCapacity: in.MinCapacity, Amenities: in.Amenities, BuildingID: in.BuildingID}
+	d := service.BookingDetails{PartySize: in.PartySize, AttendeeEmails: in.Attendees}
+	res, err := h.svc.CreateGroupBooking(u.ID, in.Start, in.End, spec, d)
+	if err != nil {
+		if res != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "conflicts": res.Conflicts}); return
+		}
+		c.JSON(bookingErrStatus(err), gin.H{"error": err.Error()}); return
+	}
+	c.JSON(http.StatusCreated, res)
+}
+
+func (h *BookingHandler) GetGroup(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	g, bs, err := h.svc.GetGroup(c.Param("id"), u.ID)
+	if err != nil { c.JSON(http.StatusNotFound, gin.H{"error": err.Error()}); return }
+	c.JSON(http.StatusOK, gin.H{"group": g, "bookings": bs})
+}
+
+// CancelGroup cancels all bookings of a group reservation at once.
+func (h *BookingHandler) CancelGroup(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	n, err := h.svc.CancelGroup(c.Param("id"), u.ID)
+	if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+	c.JSON(http.StatusOK, gin.H{"status": "cancelled", "cancelled": n})
+}
+
+// Quota shows the caller's booking limits and how much of them is left.
+func (h *BookingHandler) Quota(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	q, err := h.svc.Quota(u.ID)
+	if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"}); return }
+	c.JSON(http.StatusOK, q)
+}
+
+// Timeline returns the free/busy view of room_ids (comma separated or
+// repeated, at most 50) for the dates from..to.
+func (h *BookingHandler) Timeline(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	tls, err := h.svc.RoomTimeline(u.ID, u.IsAdmin, queryList(c, "room_ids"), c.Query("from"), c.Query("to"))
+	if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+	c.JSON(http.StatusOK, tls)
+}
+
+// RoomTimeline is Timeline for the single room in the path.
+func (h *BookingHandler) RoomTimeline(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	tls, err := h.svc.RoomTimeline(u.ID, u.IsAdmin, []string{c.Param("id")}, c.Query("from"), c.Query("to"))
+	if err != nil { c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return }
+	c.JSON(http.StatusOK, tls[0])
+}
+
+func bookingErrStatus(err error) int {
+	if errors.Is(err, service.ErrQuotaExceeded) { return http.StatusForbidden }
+	return http.StatusBadRequest
+}
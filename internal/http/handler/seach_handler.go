@@ -3,9 +3,13 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"studyroom/internal/models"
+	"studyroom/internal/repo"
 	"studyroom/internal/service"
 )
 
@@ -13,7 +17,12 @@ type SearchHandler struct{ svc service.SearchService }
 
 func NewSearchHandler(s service.SearchService) *SearchHandler { return &SearchHandler{svc: s} }
 
+// SearchRooms returns one page of available rooms as a JSON array, ordered
+// by sort (comma separated: fit, distance, favorites, name). The total
+// count and the cursor for the next page come back in the X-Total-Count and
+// X-Next-Cursor headers.
 func (h *SearchHandler) SearchRooms(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
 	start := c.Query("start")
 	end := c.Query("end")
 	minCap := 1
@@ -22,10 +31,143 @@ func (h *SearchHandler) SearchRooms(c *gin.Context) {
 			minCap = n
 		}
 	}
-	res, err := h.svc.FindAvailable(minCap, start, end)
+	f := repo.RoomFilter{MinCapacity: minCap, Amenities: queryList(c, "amenities"), BuildingID: c.Query("building_id"), FloorID: c.Query("floor_id")}
+	page, err := h.svc.Search(service.SearchQuery{
+		Filter:         f,
+		Start:          start,
+		End:            end,
+		SortBy:         queryList(c, "sort"),
+		NearBuildingID: c.Query("near_building_id"),
+		UserID:         u.ID,
+		PageSize:       queryInt(c, "page_size"),
+		Cursor:         c.Query("cursor"),
+	})
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	c.Header("X-Total-Count", strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		c.Header("X-Next-Cursor", page.NextCursor)
+	}
+	c.JSON(http.StatusOK, page.Rooms)
+}
+
+func (h *SearchHandler) ListFavorites(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	res, err := h.svc.ListFavorites(u.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *SearchHandler) AddFavorite(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	if err := h.svc.SetFavorite(u.ID, c.Param("room_id"), true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (h *SearchHandler) RemoveFavorite(c *gin.Context) {
+	u := c.MustGet("user").(*models.User)
+	if err := h.svc.SetFavorite(u.ID, c.Param("room_id"), false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// NextSlots finds the earliest free slots of duration_min minutes. It takes
+// the room filters of SearchRooms plus from, horizon_days, prefer_from,
+// prefer_to ("hh:mm") and limit. At most 50 rooms may match the filters.
+func (h *SearchHandler) NextSlots(c *gin.Context) {
+	q := service.SlotQuery{
+		Filter: repo.RoomFilter{
+			MinCapacity: queryInt(c, "min_capacity"),
+			Amenities:   queryList(c, "amenities"),
+			BuildingID:  c.Query("building_id"),
+			FloorID:     c.Query("floor_id"),
+		},
+		Duration:   time.Duration(queryInt(c, "duration_min")) * time.Minute,
+		From:       c.Query("from"),
+		Horizon:    time.Duration(queryInt(c, "horizon_days")) * 24 * time.Hour,
+		PreferFrom: c.Query("prefer_from"),
+		PreferTo:   c.Query("prefer_to"),
+		Limit:      queryInt(c, "limit"),
+	}
+	res, err := h.svc.FindNextSlots(q)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// queryInt reads an integer query parameter; missing or malformed values are 0.
+func queryInt(c *gin.Context, key string) int {
+	n, _ := strconv.Atoi(c.Query(key))
+	return n
+}
+
+// queryList reads a list given as key=a,b or key=a&key=b.
+func queryList(c *gin.Context, key string) []string {
+	var out []string
+	for _, v := range c.QueryArray(key) {
+		for _, a := range strings.Split(v, ",") {
+			if a = strings.TrimSpace(a); a != "" {
+				out = append(out, a)
+			}
+		}
+	}
+	return out
+}
+
+func (h *SearchHandler) ListAmenities(c *gin.Context) {
+	res, err := h.svc.ListAmenities()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *SearchHandler) ListCampuses(c *gin.Context) {
+	res, err := h.svc.ListCampuses()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// ListBuildings takes an optional campus_id filter.
+func (h *SearchHandler) ListBuildings(c *gin.Context) {
+	res, err := h.svc.ListBuildings(c.Query("campus_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *SearchHandler) GetBuilding(c *gin.Context) {
+	res, err := h.svc.GetBuilding(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *SearchHandler) ListFloors(c *gin.Context) {
+	res, err := h.svc.ListFloors(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, res)
 }
@@ -0,0 +1,55 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AmenityRepo is the admin-managed catalog of room amenities. Rooms refer
+// to amenities by key.
+type AmenityRepo interface {
+	Create(key, name string) error
+	List() ([]AmenityRow, error)
+	Delete(key string) error
+}
+
+type AmenityRow struct {
+	Key  string `bson:"key" json:"key"` // e.g. "whiteboard"
+	Name string `bson:"name" json:"name"`
+}
+
+type amenityRepoMongo struct{ d *mongo.Database }
+
+func NewAmenityRepoMongo(d *mongo.Database) AmenityRepo { return &amenityRepoMongo{d: d} }
+
+func (r *amenityRepoMongo) Create(key, name string) error {
+	_, err := r.d.Collection("amenities").InsertOne(context.Background(), bson.M{
+		"key": key, "name": name, "created_at": time.Now().UTC(),
+	})
+	if mongo.IsDuplicateKeyError(err) { return errors.New("amenity already exists") }
+	return err
+}
+
+func (r *amenityRepoMongo) List() ([]AmenityRow, error) {
+	cur, err := r.d.Collection("amenities").Find(context.Background(), bson.M{},
+		options.Find().SetSort(bson.D{{Key: "key", Value: 1}}))
+	if err != nil { return nil, err }
+	var out []AmenityRow
+	err = cur.All(context.Background(), &out)
+	return out, err
+}
+
+// Delete removes the amenity from the catalog and from every room.
+func (r *amenityRepoMongo) Delete(key string) error {
+	ctx := context.Background()
+	res, err := r.d.Collection("amenities").DeleteOne(ctx, bson.M{"key": key})
+	if err != nil { return err }
+	if res.DeletedCount == 0 { return errors.New("amenity not found") }
+	_, err = r.d.Collection("rooms").UpdateMany(ctx, bson.M{"amenities": key}, bson.M{"$pull": bson.M{"amenities": key}})
+	return err
+}
@@ -2,64 +2,469 @@ package repo
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type BookingRepo interface {
-	Create(roomID, userID string, start, end string) (bookingID string, err error)
+	Create(roomID, userID string, start, end time.Time, partySize int, attendeeIDs []string) (bookingID string, err error)
 	Cancel(bookingID string, userID string) error
-	HasOverlap(roomID string, start, end string) (bool, error)
-	GetByID(bookingID string) (roomID string, userID string, start, end, status string, err error)
+	HasOverlap(roomID string, start, end time.Time) (bool, error)
+	HasOverlapExcluding(roomID string, start, end time.Time, excludeID string) (bool, error)
+	Reschedule(bookingID, userID string, start, end time.Time) error
+	GetByID(bookingID string) (roomID string, userID string, start, end time.Time, status string, err error)
+	GetRow(bookingID string) (BookingRow, error)
+	CreateInSeries(roomID, userID, seriesID string, start, end time.Time, partySize int, attendeeIDs []string) (bookingID string, err error)
+	ListBySeries(seriesID string) ([]BookingRow, error)
+	CheckIn(bookingID, userID string) error
+	ListUnchecked(startBefore, endAfter time.Time) ([]BookingRow, error)
+	MarkNoShow(bookingID string) (bool, error)
+	ListByUser(userID string, endAfter time.Time) ([]BookingRow, error)
+	ListVisibleTo(userID string, endAfter time.Time) ([]BookingRow, error)
+	CreateHold(roomID, userID string, start, end time.Time, partySize int, attendeeIDs []string, expires time.Time) (bookingID string, err error)
+	ConfirmHold(bookingID, userID string) error
+	ListExpiredHolds(now time.Time) ([]BookingRow, error)
+	ExpireHold(bookingID string) (bool, error)
+	CreateInGroup(roomID, userID, groupID string, start, end time.Time, partySize int, attendeeIDs []string) (bookingID string, err error)
+	ListByGroup(groupID string) ([]BookingRow, error)
+	CreateOffer(roomID, userID string, start, end time.Time, partySize int, expires time.Time) (bookingID string, err error)
+	ListOffers(userID string) ([]BookingRow, error)
+	ListOccupying(roomID string, start, end time.Time) ([]BookingRow, error)
+	ListOccupyingRooms(roomIDs []string, start, end time.Time) ([]BookingRow, error)
+	CancelWithReason(bookingID, reason string) (bool, error)
+	ListByRoom(roomID string, endAfter time.Time) ([]BookingRow, error)
+	HasHistory(roomID string) (bool, error)
+}
+
+type BookingRow struct {
+	ID        string    `json:"id"`
+	RoomID    string    `json:"room_id"`
+	UserID    string    `json:"user_id"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Status    string    `json:"status"`
+	SeriesID  string    `json:"series_id,omitempty"`
+	GroupID   string    `json:"group_id,omitempty"`
+	PartySize int       `json:"party_size"`
+	Attendees []string  `json:"attendee_ids,omitempty"`
+
+	HoldExpiresAt *time.Time `json:"hold_expires_at,omitempty"` // only while status is "held"
+	Offer         bool       `json:"offer,omitempty"`           // hold created for a waitlisted user
+	CancelReason  string     `json:"cancel_reason,omitempty"`   // set when an admin action cancelled it
+}
+
+type bookingDoc struct {
+	ID        primitive.ObjectID   `bson:"_id"`
+	RoomID    primitive.ObjectID   `bson:"room_id"`
+	UserID    primitive.ObjectID   `bson:"user_id"`
+	Start     time.Time            `bson:"start_at"`
+	End       time.Time            `bson:"end_at"`
+	Status    string               `bson:"status"`
+	SeriesID  primitive.ObjectID   `bson:"series_id,omitempty"`
+	GroupID   primitive.ObjectID   `bson:"group_id,omitempty"`
+	PartySize int                  `bson:"party_size"`
+	Attendees []primitive.ObjectID `bson:"attendee_ids"`
+
+	HoldExpiresAt *time.Time `bson:"hold_expires_at,omitempty"`
+	Offer         bool       `bson:"offer,omitempty"`
+	CancelReason  string     `bson:"cancel_reason,omitempty"`
+}
+
+func (d bookingDoc) row() BookingRow {
+	out := BookingRow{
+		ID: oidHex(d.ID), RoomID: oidHex(d.RoomID), UserID: oidHex(d.UserID),
+		Start: d.Start, End: d.End, Status: d.Status, PartySize: d.PartySize,
+		HoldExpiresAt: d.HoldExpiresAt, Offer: d.Offer, CancelReason: d.CancelReason,
+	}
+	if !d.SeriesID.IsZero() { out.SeriesID = oidHex(d.SeriesID) }
+	if !d.GroupID.IsZero() { out.GroupID = oidHex(d.GroupID) }
+	for _, a := range d.Attendees { out.Attendees = append(out.Attendees, oidHex(a)) }
+	return out
 }
 
 type bookingRepoMongo struct{ d *mongo.Database }
 
 func NewBookingRepoMongo(d *mongo.Database) BookingRepo { return &bookingRepoMongo{d: d} }
 
-func (r *bookingRepoMongo) Create(roomID, userID string, start, end string) (string, error) {
+// occupying matches the bookings that block their slot: confirmed ones and
+// holds that have not expired yet.
+func occupying() bson.M {
+	return bson.M{"$or": []bson.M{
+		{"status": "confirmed"},
+		{"status": "held", "hold_expires_at": bson.M{"$gt": time.Now().UTC()}},
+	}}
+}
+
+func (r *bookingRepoMongo) Create(roomID, userID string, start, end time.Time, partySize int, attendeeIDs []string) (string, error) {
 	roid, err := mustOID(roomID); if err != nil { return "", err }
 	uid,  err := mustOID(userID); if err != nil { return "", err }
+	aids, err := oids(attendeeIDs); if err != nil { return "", err }
 	res, err := r.d.Collection("bookings").InsertOne(context.Background(), bson.M{
 		"room_id": roid, "user_id": uid,
-		"start_at": start, "end_at": end, "status": "confirmed",
+		"start_at": start.UTC(), "end_at": end.UTC(), "status": "confirmed",
+		"party_size": partySize, "attendee_ids": aids,
 	})
 	if err != nil { return "", err }
 	return oidHex(res.InsertedID.(primitive.ObjectID)), nil
 }
 
+// CreateInSeries creates a confirmed booking that belongs to a recurring series.
+func (r *bookingRepoMongo) CreateInSeries(roomID, userID, seriesID string, start, end time.Time, partySize int, attendeeIDs []string) (string, error) {
+	roid, err := mustOID(roomID);   if err != nil { return "", err }
+	uid,  err := mustOID(userID);   if err != nil { return "", err }
+	sid,  err := mustOID(seriesID); if err != nil { return "", err }
+	aids, err := oids(attendeeIDs); if err != nil { return "", err }
+	res, err := r.d.Collection("bookings").InsertOne(context.Background(), bson.M{
+		"room_id": roid, "user_id": uid, "series_id": sid,
+		"start_at": start.UTC(), "end_at": end.UTC(), "status": "confirmed",
+		"party_size": partySize, "attendee_ids": aids,
+	})
+	if err != nil { return "", err }
+	return oidHex(res.InsertedID.(primitive.ObjectID)), nil
+}
+
+// ListBySeries returns every occurrence of a series (any status), ordered by start.
+func (r *bookingRepoMongo) ListBySeries(seriesID string) ([]BookingRow, error) {
+	sid, err := mustOID(seriesID); if err != nil { return nil, err }
+	cur, err := r.d.Collection("bookings").Find(context.Background(), bson.M{"series_id": sid},
+		options.Find().SetSort(bson.D{{Key: "start_at", Value: 1}}))
+	if err != nil { return nil, err }
+	defer cur.Close(context.Background())
+	var out []BookingRow
+	for cur.Next(context.Background()) {
+		var doc bookingDoc
+		if err := cur.Decode(&doc); err != nil { return nil, err }
+		out = append(out, doc.row())
+	}
+	return out, cur.Err()
+}
+
+// CreateInGroup creates a confirmed booking that belongs to a group reservation.
+func (r *bookingRepoMongo) CreateInGroup(roomID, userID, groupID string, start, end time.Time, partySize int, attendeeIDs []string) (string, error) {
+	roid, err := mustOID(roomID);   if err != nil { return "", err }
+	uid,  err := mustOID(userID);   if err != nil { return "", err }
+	gid,  err := mustOID(groupID);  if err != nil { return "", err }
+	aids, err := oids(attendeeIDs); if err != nil { return "", err }
+	res, err := r.d.Collection("bookings").InsertOne(context.Background(), bson.M{
+		"room_id": roid, "user_id": uid, "group_id": gid,
+		"start_at": start.UTC(), "end_at": end.UTC(), "status": "confirmed",
+		"party_size": partySize, "attendee_ids": aids,
+	})
+	if err != nil { return "", err }
+	return oidHex(res.InsertedID.(primitive.ObjectID)), nil
+}
+
+// ListByGroup returns every booking of a group (any status).
+func (r *bookingRepoMongo) ListByGroup(groupID string) ([]BookingRow, error) {
+	gid, err := mustOID(groupID); if err != nil { return nil, err }
+	cur, err := r.d.Collection("bookings").Find(context.Background(), bson.M{"group_id": gid})
+	if err != nil { return nil, err }
+	defer cur.Close(context.Background())
+	var out []BookingRow
+	for cur.Next(context.Background()) {
+		var doc bookingDoc
+		if err := cur.Decode(&doc); err != nil { return nil, err }
+		out = append(out, doc.row())
+	}
+	return out, cur.Err()
+}
+
+func (r *bookingRepoMongo) CheckIn(bookingID, userID string) error {
+	bid, err := mustOID(bookingID); if err != nil { return err }
+	uid, err := mustOID(userID);   if err != nil { return err }
+	res, err := r.d.Collection("bookings").UpdateOne(context.Background(),
+		bson.M{"_id": bid, "user_id": uid, "status": "confirmed", "checked_in_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"checked_in_at": time.Now().UTC()}},
+	)
+	if err != nil { return err }
+	if res.MatchedCount == 0 { return errors.New("booking not found or already checked in") }
+	return nil
+}
+
+// ListUnchecked returns confirmed bookings without a check-in that started
+// at or before startBefore and are still running after endAfter.
+func (r *bookingRepoMongo) ListUnchecked(startBefore, endAfter time.Time) ([]BookingRow, error) {
+	cur, err := r.d.Collection("bookings").Find(context.Background(), bson.M{
+		"status": "confirmed", "checked_in_at": bson.M{"$exists": false},
+		"start_at": bson.M{"$lte": startBefore.UTC()}, "end_at": bson.M{"$gt": endAfter.UTC()},
+	})
+	if err != nil { return nil, err }
+	defer cur.Close(context.Background())
+	var out []BookingRow
+	for cur.Next(context.Background()) {
+		var doc bookingDoc
+		if err := cur.Decode(&doc); err != nil { return nil, err }
+		out = append(out, doc.row())
+	}
+	return out, cur.Err()
+}
+
+// MarkNoShow releases an unchecked booking; false means it was checked in,
+// cancelled or already released in the meantime.
+func (r *bookingRepoMongo) MarkNoShow(bookingID string) (bool, error) {
+	bid, err := mustOID(bookingID); if err != nil { return false, err }
+	res, err := r.d.Collection("bookings").UpdateOne(context.Background(),
+		bson.M{"_id": bid, "status": "confirmed", "checked_in_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"status": "no_show"}},
+	)
+	if err != nil { return false, err }
+	return res.ModifiedCount > 0, nil
+}
+
+// ListByUser returns the user's confirmed bookings and live holds ending
+// after endAfter, ordered by start.
+func (r *bookingRepoMongo) ListByUser(userID string, endAfter time.Time) ([]BookingRow, error) {
+	uid, err := mustOID(userID); if err != nil { return nil, err }
+	f := occupying()
+	f["user_id"], f["end_at"] = uid, bson.M{"$gt": endAfter.UTC()}
+	cur, err := r.d.Collection("bookings").Find(context.Background(), f,
+		options.Find().SetSort(bson.D{{Key: "start_at", Value: 1}}))
+	if err != nil { return nil, err }
+	defer cur.Close(context.Background())
+	var out []BookingRow
+	for cur.Next(context.Background()) {
+		var doc bookingDoc
+		if err := cur.Decode(&doc); err != nil { return nil, err }
+		out = append(out, doc.row())
+	}
+	return out, cur.Err()
+}
+
+// ListVisibleTo returns confirmed bookings ending after endAfter that userID
+// either owns or attends, ordered by start.
+func (r *bookingRepoMongo) ListVisibleTo(userID string, endAfter time.Time) ([]BookingRow, error) {
+	uid, err := mustOID(userID); if err != nil { return nil, err }
+	cur, err := r.d.Collection("bookings").Find(context.Background(), bson.M{
+		"$or":    []bson.M{{"user_id": uid}, {"attendee_ids": uid}},
+		"status": "confirmed", "end_at": bson.M{"$gt": endAfter.UTC()},
+	}, options.Find().SetSort(bson.D{{Key: "start_at", Value: 1}}))
+	if err != nil { return nil, err }
+	defer cur.Close(context.Background())
+	var out []BookingRow
+	for cur.Next(context.Background()) {
+		var doc bookingDoc
+		if err := cur.Decode(&doc); err != nil { return nil, err }
+		out = append(out, doc.row())
+	}
+	return out, cur.Err()
+}
+
 func (r *bookingRepoMongo) Cancel(bookingID string, userID string) error {
 	bid, err := mustOID(bookingID); if err != nil { return err }
 	uid, err := mustOID(userID);   if err != nil { return err }
 	_, err = r.d.Collection("bookings").UpdateOne(context.Background(),
-		bson.M{"_id": bid, "user_id": uid, "status": "confirmed"},
+		bson.M{"_id": bid, "user_id": uid, "status": bson.M{"$in": []string{"confirmed", "held"}}},
 		bson.M{"$set": bson.M{"status": "cancelled"}},
 	)
 	return err
 }
 
-func (r *bookingRepoMongo) HasOverlap(roomID string, start, end string) (bool, error) {
+// ListOccupying returns the bookings blocking any part of [start, end) in
+// the room, ordered by start.
+func (r *bookingRepoMongo) ListOccupying(roomID string, start, end time.Time) ([]BookingRow, error) {
+	return r.ListOccupyingRooms([]string{roomID}, start, end)
+}
+
+// ListOccupyingRooms is ListOccupying for several rooms in one query.
+func (r *bookingRepoMongo) ListOccupyingRooms(roomIDs []string, start, end time.Time) ([]BookingRow, error) {
+	roids, err := oids(roomIDs); if err != nil { return nil, err }
+	f := occupying()
+	f["room_id"] = bson.M{"$in": roids}
+	f["end_at"], f["start_at"] = bson.M{"$gt": start.UTC()}, bson.M{"$lt": end.UTC()}
+	cur, err := r.d.Collection("bookings").Find(context.Background(), f,
+		options.Find().SetSort(bson.D{{Key: "start_at", Value: 1}}))
+	if err != nil { return nil, err }
+	defer cur.Close(context.Background())
+	var out []BookingRow
+	for cur.Next(context.Background()) {
+		var doc bookingDoc
+		if err := cur.Decode(&doc); err != nil { return nil, err }
+		out = append(out, doc.row())
+	}
+	return out, cur.Err()
+}
+
+// ListByRoom returns the room's active bookings ending after endAfter,
+// ordered by start.
+func (r *bookingRepoMongo) ListByRoom(roomID string, endAfter time.Time) ([]BookingRow, error) {
+	roid, err := mustOID(roomID); if err != nil { return nil, err }
+	f := occupying()
+	f["room_id"], f["end_at"] = roid, bson.M{"$gt": endAfter.UTC()}
+	cur, err := r.d.Collection("bookings").Find(context.Background(), f,
+		options.Find().SetSort(bson.D{{Key: "start_at", Value: 1}}))
+	if err != nil { return nil, err }
+	defer cur.Close(context.Background())
+	var out []BookingRow
+	for cur.Next(context.Background()) {
+		var doc bookingDoc
+		if err := cur.Decode(&doc); err != nil { return nil, err }
+		out = append(out, doc.row())
+	}
+	return out, cur.Err()
+}
+
+// HasHistory reports whether any booking, in whatever state, refers to the room.
+func (r *bookingRepoMongo) HasHistory(roomID string) (bool, error) {
 	roid, err := mustOID(roomID); if err != nil { return false, err }
-	cnt, err := r.d.Collection("bookings").CountDocuments(context.Background(), bson.M{
-		"room_id": roid, "status": "confirmed",
-		"$nor": []bson.M{{"end_at": bson.M{"$lte": start}}, {"start_at": bson.M{"$gte": end}}},
-	})
+	cnt, err := r.d.Collection("bookings").CountDocuments(context.Background(), bson.M{"room_id": roid},
+		options.Count().SetLimit(1))
+	return cnt > 0, err
+}
+
+// CancelWithReason cancels a confirmed or held booking on someone else's
+// behalf; false means it was no longer active.
+func (r *bookingRepoMongo) CancelWithReason(bookingID, reason string) (bool, error) {
+	bid, err := mustOID(bookingID); if err != nil { return false, err }
+	res, err := r.d.Collection("bookings").UpdateOne(context.Background(),
+		bson.M{"_id": bid, "status": bson.M{"$in": []string{"confirmed", "held"}}},
+		bson.M{"$set": bson.M{"status": "cancelled", "cancel_reason": reason}},
+	)
+	if err != nil { return false, err }
+	return res.ModifiedCount > 0, nil
+}
+
+func (r *bookingRepoMongo) HasOverlap(roomID string, start, end time.Time) (bool, error) {
+	roid, err := mustOID(roomID); if err != nil { return false, err }
+	f := occupying()
+	f["room_id"] = roid
+	f["end_at"], f["start_at"] = bson.M{"$gt": start.UTC()}, bson.M{"$lt": end.UTC()}
+	cnt, err := r.d.Collection("bookings").CountDocuments(context.Background(), f)
+	return cnt > 0, err
+}
+
+// HasOverlapExcluding is HasOverlap but ignores the booking excludeID, so a
+// booking can be checked against its own room without conflicting with itself.
+func (r *bookingRepoMongo) HasOverlapExcluding(roomID string, start, end time.Time, excludeID string) (bool, error) {
+	roid, err := mustOID(roomID); if err != nil { return false, err }
+	bid,  err := mustOID(excludeID); if err != nil { return false, err }
+	f := occupying()
+	f["room_id"], f["_id"] = roid, bson.M{"$ne": bid}
+	f["end_at"], f["start_at"] = bson.M{"$gt": start.UTC()}, bson.M{"$lt": end.UTC()}
+	cnt, err := r.d.Collection("bookings").CountDocuments(context.Background(), f)
 	return cnt > 0, err
 }
 
-func (r *bookingRepoMongo) GetByID(bookingID string) (string, string, string, string, string, error) {
-	bid, err := mustOID(bookingID); if err != nil { return "", "", "", "", "", err }
+// Reschedule moves a confirmed booking to [start, end) in a single update.
+func (r *bookingRepoMongo) Reschedule(bookingID, userID string, start, end time.Time) error {
+	bid, err := mustOID(bookingID); if err != nil { return err }
+	uid, err := mustOID(userID);   if err != nil { return err }
+	res, err := r.d.Collection("bookings").UpdateOne(context.Background(),
+		bson.M{"_id": bid, "user_id": uid, "status": "confirmed"},
+		bson.M{"$set": bson.M{"start_at": start.UTC(), "end_at": end.UTC()}},
+	)
+	if err != nil { return err }
+	if res.MatchedCount == 0 { return errors.New("booking not found") }
+	return nil
+}
+
+func (r *bookingRepoMongo) GetByID(bookingID string) (string, string, time.Time, time.Time, string, error) {
+	bid, err := mustOID(bookingID); if err != nil { return "", "", time.Time{}, time.Time{}, "", err }
 	var doc struct {
 		ID     primitive.ObjectID `bson:"_id"`
 		RoomID primitive.ObjectID `bson:"room_id"`
 		UserID primitive.ObjectID `bson:"user_id"`
-		Start  string             `bson:"start_at"`
-		End    string             `bson:"end_at"`
+		Start  time.Time          `bson:"start_at"`
+		End    time.Time          `bson:"end_at"`
 		Status string             `bson:"status"`
 	}
 	err = r.d.Collection("bookings").FindOne(context.Background(), bson.M{"_id": bid}).Decode(&doc)
-	if err != nil { return "", "", "", "", "", err }
+	if err != nil { return "", "", time.Time{}, time.Time{}, "", err }
 	return oidHex(doc.RoomID), oidHex(doc.UserID), doc.Start, doc.End, doc.Status, nil
-}
\ No newline at end of file
+}
+
+// GetRow is GetByID returning the whole booking.
+func (r *bookingRepoMongo) GetRow(bookingID string) (BookingRow, error) {
+	bid, err := mustOID(bookingID); if err != nil { return BookingRow{}, err }
+	var doc bookingDoc
+	if err := r.d.Collection("bookings").FindOne(context.Background(), bson.M{"_id": bid}).Decode(&doc); err != nil { return BookingRow{}, err }
+	return doc.row(), nil
+}
+
+// CreateHold creates a tentative booking that blocks its slot until expires.
+func (r *bookingRepoMongo) CreateHold(roomID, userID string, start, end time.Time, partySize int, attendeeIDs []string, expires time.Time) (string, error) {
+	roid, err := mustOID(roomID);   if err != nil { return "", err }
+	uid,  err := mustOID(userID);   if err != nil { return "", err }
+	aids, err := oids(attendeeIDs); if err != nil { return "", err }
+	res, err := r.d.Collection("bookings").InsertOne(context.Background(), bson.M{
+		"room_id": roid, "user_id": uid,
+		"start_at": start.UTC(), "end_at": end.UTC(), "status": "held", "hold_expires_at": expires.UTC(),
+		"party_size": partySize, "attendee_ids": aids,
+	})
+	if err != nil { return "", err }
+	return oidHex(res.InsertedID.(primitive.ObjectID)), nil
+}
+
+// CreateOffer holds a freed slot for a waitlisted user until they accept it
+// or expires passes.
+func (r *bookingRepoMongo) CreateOffer(roomID, userID string, start, end time.Time, partySize int, expires time.Time) (string, error) {
+	roid, err := mustOID(roomID); if err != nil { return "", err }
+	uid,  err := mustOID(userID); if err != nil { return "", err }
+	res, err := r.d.Collection("bookings").InsertOne(context.Background(), bson.M{
+		"room_id": roid, "user_id": uid,
+		"start_at": start.UTC(), "end_at": end.UTC(), "status": "held", "hold_expires_at": expires.UTC(), "offer": true,
+		"party_size": partySize, "attendee_ids": []primitive.ObjectID{},
+	})
+	if err != nil { return "", err }
+	return oidHex(res.InsertedID.(primitive.ObjectID)), nil
+}
+
+// ListOffers returns the user's open waitlist offers, soonest expiry first.
+func (r *bookingRepoMongo) ListOffers(userID string) ([]BookingRow, error) {
+	uid, err := mustOID(userID); if err != nil { return nil, err }
+	cur, err := r.d.Collection("bookings").Find(context.Background(), bson.M{
+		"user_id": uid, "status": "held", "offer": true, "hold_expires_at": bson.M{"$gt": time.Now().UTC()},
+	}, options.Find().SetSort(bson.D{{Key: "hold_expires_at", Value: 1}}))
+	if err != nil { return nil, err }
+	defer cur.Close(context.Background())
+	var out []BookingRow
+	for cur.Next(context.Background()) {
+		var doc bookingDoc
+		if err := cur.Decode(&doc); err != nil { return nil, err }
+		out = append(out, doc.row())
+	}
+	return out, cur.Err()
+}
+
+// ConfirmHold turns an unexpired hold into a confirmed booking.
+func (r *bookingRepoMongo) ConfirmHold(bookingID, userID string) error {
+	bid, err := mustOID(bookingID); if err != nil { return err }
+	uid, err := mustOID(userID);   if err != nil { return err }
+	res, err := r.d.Collection("bookings").UpdateOne(context.Background(),
+		bson.M{"_id": bid, "user_id": uid, "status": "held", "hold_expires_at": bson.M{"$gt": time.Now().UTC()}},
+		bson.M{"$set": bson.M{"status": "confirmed"}, "$unset": bson.M{"hold_expires_at": ""}},
+	)
+	if err != nil { return err }
+	if res.MatchedCount == 0 { return errors.New("hold not found or expired") }
+	return nil
+}
+
+func (r *bookingRepoMongo) ListExpiredHolds(now time.Time) ([]BookingRow, error) {
+	cur, err := r.d.Collection("bookings").Find(context.Background(),
+		bson.M{"status": "held", "hold_expires_at": bson.M{"$lte": now.UTC()}})
+	if err != nil { return nil, err }
+	defer cur.Close(context.Background())
+	var out []BookingRow
+	for cur.Next(context.Background()) {
+		var doc bookingDoc
+		if err := cur.Decode(&doc); err != nil { return nil, err }
+		out = append(out, doc.row())
+	}
+	return out, cur.Err()
+}
+
+// ExpireHold releases a hold; false means it was confirmed, cancelled or
+// already expired in the meantime.
+func (r *bookingRepoMongo) ExpireHold(bookingID string) (bool, error) {
+	bid, err := mustOID(bookingID); if err != nil { return false, err }
+	res, err := r.d.Collection("bookings").UpdateOne(context.Background(),
+		bson.M{"_id": bid, "status": "held"},
+		bson.M{"$set": bson.M{"status": "expired"}},
+	)
+	if err != nil { return false, err }
+	return res.ModifiedCount > 0, nil
+}
@@ -0,0 +1,67 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GroupRepo stores group reservations; the member bookings themselves are
+// ordinary bookings carrying the group_id.
+type GroupRepo interface {
+	Create(g GroupRow) (id string, err error)
+	GetByID(groupID string) (GroupRow, error)
+	SetStatus(groupID string, status string) error
+}
+
+type GroupRow struct {
+	ID      string    `json:"id"`
+	UserID  string    `json:"user_id"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	RoomIDs []string  `json:"room_ids"`
+	Status  string    `json:"status"` // "active" | "cancelled"
+}
+
+type groupRepoMongo struct{ d *mongo.Database }
+
+func NewGroupRepoMongo(d *mongo.Database) GroupRepo { return &groupRepoMongo{d: d} }
+
+func (r *groupRepoMongo) Create(g GroupRow) (string, error) {
+	uid,  err := mustOID(g.UserID);  if err != nil { return "", err }
+	rids, err := oids(g.RoomIDs);    if err != nil { return "", err }
+	res, err := r.d.Collection("booking_groups").InsertOne(context.Background(), bson.M{
+		"user_id": uid, "room_ids": rids,
+		"start_at": g.Start.UTC(), "end_at": g.End.UTC(),
+		"status": g.Status, "created_at": time.Now().UTC(),
+	})
+	if err != nil { return "", err }
+	return oidHex(res.InsertedID.(primitive.ObjectID)), nil
+}
+
+func (r *groupRepoMongo) GetByID(groupID string) (GroupRow, error) {
+	gid, err := mustOID(groupID); if err != nil { return GroupRow{}, err }
+	var doc struct {
+		ID      primitive.ObjectID   `bson:"_id"`
+		UserID  primitive.ObjectID   `bson:"user_id"`
+		RoomIDs []primitive.ObjectID `bson:"room_ids"`
+		Start   time.Time            `bson:"start_at"`
+		End     time.Time            `bson:"end_at"`
+		Status  string               `bson:"status"`
+	}
+	err = r.d.Collection("booking_groups").FindOne(context.Background(), bson.M{"_id": gid}).Decode(&doc)
+	if err != nil { return GroupRow{}, err }
+	out := GroupRow{ID: oidHex(doc.ID), UserID: oidHex(doc.UserID), Start: doc.Start, End: doc.End, Status: doc.Status}
+	for _, id := range doc.RoomIDs { out.RoomIDs = append(out.RoomIDs, oidHex(id)) }
+	return out, nil
+}
+
+func (r *groupRepoMongo) SetStatus(groupID string, status string) error {
+	gid, err := mustOID(groupID); if err != nil { return err }
+	_, err = r.d.Collection("booking_groups").UpdateOne(context.Background(),
+		bson.M{"_id": gid}, bson.M{"$set": bson.M{"status": status}})
+	return err
+}
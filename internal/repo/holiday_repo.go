@@ -0,0 +1,96 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// HolidayRepo stores the closure calendar. A holiday closes every room, or
+// every room of one building, for the whole of its date, read in each
+// room's time zone; the room availability checks consult the same collection.
+type HolidayRepo interface {
+	Add(h HolidayRow) (id string, err error)
+	List(from, to string) ([]HolidayRow, error)
+	Delete(holidayID string) error
+}
+
+type HolidayRow struct {
+	ID         string `json:"id"`
+	Date       string `json:"date"` // YYYY-MM-DD
+	Name       string `json:"name"`
+	BuildingID string `json:"building_id,omitempty"` // empty: institution-wide
+}
+
+type holidayRepoMongo struct{ d *mongo.Database }
+
+func NewHolidayRepoMongo(d *mongo.Database) HolidayRepo { return &holidayRepoMongo{d: d} }
+
+// Add saves h unless a holiday with the same date, name and building is
+// already saved, in which case it returns that one's id; importing the same
+// calendar twice therefore adds nothing.
+func (r *holidayRepoMongo) Add(h HolidayRow) (string, error) {
+	f := bson.M{"date": h.Date, "name": h.Name, "building_id": bson.M{"$exists": false}}
+	onInsert := bson.M{"created_at": time.Now().UTC()}
+	if h.BuildingID != "" {
+		bid, err := mustOID(h.BuildingID); if err != nil { return "", err }
+		f["building_id"], onInsert["building_id"] = bid, bid
+	}
+	var doc struct{ ID primitive.ObjectID `bson:"_id"` }
+	err := r.d.Collection("holidays").FindOneAndUpdate(context.Background(), f, bson.M{"$setOnInsert": onInsert},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After).SetProjection(bson.M{"_id": 1}),
+	).Decode(&doc)
+	if err != nil { return "", err }
+	return oidHex(doc.ID), nil
+}
+
+// List returns the holidays dated from..to inclusive (either may be empty
+// for no bound), ordered by date.
+func (r *holidayRepoMongo) List(from, to string) ([]HolidayRow, error) {
+	rng := bson.M{}
+	if from != "" { rng["$gte"] = from }
+	if to != "" { rng["$lte"] = to }
+	f := bson.M{}
+	if len(rng) > 0 { f["date"] = rng }
+	cur, err := r.d.Collection("holidays").Find(context.Background(), f,
+		options.Find().SetSort(bson.D{{Key: "date", Value: 1}}))
+	if err != nil { return nil, err }
+	defer cur.Close(context.Background())
+	var out []HolidayRow
+	for cur.Next(context.Background()) {
+		var doc struct {
+			ID         primitive.ObjectID `bson:"_id"`
+			Date       string             `bson:"date"`
+			Name       string             `bson:"name"`
+			BuildingID primitive.ObjectID `bson:"building_id,omitempty"`
+		}
+		if err := cur.Decode(&doc); err != nil { return nil, err }
+		h := HolidayRow{ID: oidHex(doc.ID), Date: doc.Date, Name: doc.Name}
+		if !doc.BuildingID.IsZero() { h.BuildingID = oidHex(doc.BuildingID) }
+		out = append(out, h)
+	}
+	return out, cur.Err()
+}
+
+func (r *holidayRepoMongo) Delete(holidayID string) error {
+	hid, err := mustOID(holidayID); if err != nil { return err }
+	res, err := r.d.Collection("holidays").DeleteOne(context.Background(), bson.M{"_id": hid})
+	if err != nil { return err }
+	if res.DeletedCount == 0 { return errors.New("holiday not found") }
+	return nil
+}
+
+// localDays lists the local dates (YYYY-MM-DD) that [start, end) touches in loc.
+func localDays(loc *time.Location, start, end time.Time) []string {
+	var out []string
+	ls := start.In(loc)
+	for day := time.Date(ls.Year(), ls.Month(), ls.Day(), 0, 0, 0, 0, loc); day.Before(end); day = day.AddDate(0, 0, 1) {
+		out = append(out, day.Format(DateLayout))
+	}
+	return out
+}
@@ -0,0 +1,192 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LocationRepo stores where rooms are: campuses contain buildings, which
+// have floors. Rooms point at a building and optionally a floor.
+type LocationRepo interface {
+	CreateCampus(name string) (id string, err error)
+	ListCampuses() ([]CampusRow, error)
+	CreateBuilding(b BuildingRow) (id string, err error)
+	UpdateBuilding(b BuildingRow) error
+	GetBuilding(buildingID string) (BuildingRow, error)
+	ListBuildings(campusID string) ([]BuildingRow, error)
+	CreateFloor(buildingID string, level int, name string) (id string, err error)
+	GetFloor(floorID string) (FloorRow, error)
+	ListFloors(buildingID string) ([]FloorRow, error)
+}
+
+type CampusRow struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// BuildingRow carries the building's location metadata. Hours are the
+// default weekly opening hours of its rooms, used for rooms that have no
+// opening-hours template of their own.
+type BuildingRow struct {
+	ID        string       `json:"id"`
+	CampusID  string       `json:"campus_id"`
+	Name      string       `json:"name"`
+	Address   string       `json:"address,omitempty"`
+	Latitude  float64      `json:"latitude,omitempty"`
+	Longitude float64      `json:"longitude,omitempty"`
+	Hours     []HoursRange `json:"hours,omitempty"`
+	TimeZone  string       `json:"time_zone,omitempty"` // IANA name for rooms without a zone of their own
+}
+
+type FloorRow struct {
+	ID         string `json:"id"`
+	BuildingID string `json:"building_id"`
+	Level      int    `json:"level"` // 0 = ground floor, negative below ground
+	Name       string `json:"name,omitempty"`
+}
+
+type buildingDoc struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	CampusID  primitive.ObjectID `bson:"campus_id"`
+	Name      string             `bson:"name"`
+	Address   string             `bson:"address,omitempty"`
+	Latitude  float64            `bson:"latitude,omitempty"`
+	Longitude float64            `bson:"longitude,omitempty"`
+	Hours     []HoursRange       `bson:"hours,omitempty"`
+	TimeZone  string             `bson:"time_zone,omitempty"`
+}
+
+func (d buildingDoc) row() BuildingRow {
+	return BuildingRow{ID: oidHex(d.ID), CampusID: oidHex(d.CampusID), Name: d.Name, Address: d.Address,
+		Latitude: d.Latitude, Longitude: d.Longitude, Hours: d.Hours, TimeZone: d.TimeZone}
+}
+
+type floorDoc struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	BuildingID primitive.ObjectID `bson:"building_id"`
+	Level      int                `bson:"level"`
+	Name       string             `bson:"name,omitempty"`
+}
+
+func (d floorDoc) row() FloorRow {
+	return FloorRow{ID: oidHex(d.ID), BuildingID: oidHex(d.BuildingID), Level: d.Level, Name: d.Name}
+}
+
+type locationRepoMongo struct{ d *mongo.Database }
+
+func NewLocationRepoMongo(d *mongo.Database) LocationRepo { return &locationRepoMongo{d: d} }
+
+func (r *locationRepoMongo) CreateCampus(name string) (string, error) {
+	res, err := r.d.Collection("campuses").InsertOne(context.Background(), bson.M{"name": name, "created_at": time.Now().UTC()})
+	if mongo.IsDuplicateKeyError(err) { return "", errors.New("campus already exists") }
+	if err != nil { return "", err }
+	return oidHex(res.InsertedID.(primitive.ObjectID)), nil
+}
+
+func (r *locationRepoMongo) ListCampuses() ([]CampusRow, error) {
+	cur, err := r.d.Collection("campuses").Find(context.Background(), bson.M{},
+		options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil { return nil, err }
+	defer cur.Close(context.Background())
+	var out []CampusRow
+	for cur.Next(context.Background()) {
+		var doc struct {
+			ID   primitive.ObjectID `bson:"_id"`
+			Name string             `bson:"name"`
+		}
+		if err := cur.Decode(&doc); err != nil { return nil, err }
+		out = append(out, CampusRow{ID: oidHex(doc.ID), Name: doc.Name})
+	}
+	return out, cur.Err()
+}
+
+func (r *locationRepoMongo) CreateBuilding(b BuildingRow) (string, error) {
+	cid, err := mustOID(b.CampusID); if err != nil { return "", err }
+	doc := buildingDoc{ID: primitive.NewObjectID(), CampusID: cid, Name: b.Name, Address: b.Address,
+		Latitude: b.Latitude, Longitude: b.Longitude, Hours: b.Hours, TimeZone: b.TimeZone}
+	_, err = r.d.Collection("buildings").InsertOne(context.Background(), doc)
+	if mongo.IsDuplicateKeyError(err) { return "", errors.New("building already exists on this campus") }
+	if err != nil { return "", err }
+	return oidHex(doc.ID), nil
+}
+
+// UpdateBuilding replaces the building's name and metadata; it stays on its campus.
+func (r *locationRepoMongo) UpdateBuilding(b BuildingRow) error {
+	bid, err := mustOID(b.ID); if err != nil { return err }
+	res, err := r.d.Collection("buildings").UpdateOne(context.Background(), bson.M{"_id": bid}, bson.M{"$set": bson.M{
+		"name": b.Name, "address": b.Address, "latitude": b.Latitude, "longitude": b.Longitude, "hours": b.Hours,
+		"time_zone": b.TimeZone,
+	}})
+	if mongo.IsDuplicateKeyError(err) { return errors.New("building already exists on this campus") }
+	if err != nil { return err }
+	if res.MatchedCount == 0 { return mongo.ErrNoDocuments }
+	return nil
+}
+
+func (r *locationRepoMongo) GetBuilding(buildingID string) (BuildingRow, error) {
+	bid, err := mustOID(buildingID); if err != nil { return BuildingRow{}, err }
+	var doc buildingDoc
+	err = r.d.Collection("buildings").FindOne(context.Background(), bson.M{"_id": bid}).Decode(&doc)
+	if err != nil { return BuildingRow{}, err }
+	return doc.row(), nil
+}
+
+// ListBuildings returns the campus's buildings, or all of them when
+// campusID is empty, ordered by name.
+func (r *locationRepoMongo) ListBuildings(campusID string) ([]BuildingRow, error) {
+	f := bson.M{}
+	if campusID != "" {
+		cid, err := mustOID(campusID); if err != nil { return nil, err }
+		f["campus_id"] = cid
+	}
+	cur, err := r.d.Collection("buildings").Find(context.Background(), f,
+		options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil { return nil, err }
+	defer cur.Close(context.Background())
+	var out []BuildingRow
+	for cur.Next(context.Background()) {
+		var doc buildingDoc
+		if err := cur.Decode(&doc); err != nil { return nil, err }
+		out = append(out, doc.row())
+	}
+	return out, cur.Err()
+}
+
+func (r *locationRepoMongo) CreateFloor(buildingID string, level int, name string) (string, error) {
+	bid, err := mustOID(buildingID); if err != nil { return "", err }
+	doc := floorDoc{ID: primitive.NewObjectID(), BuildingID: bid, Level: level, Name: name}
+	_, err = r.d.Collection("floors").InsertOne(context.Background(), doc)
+	if mongo.IsDuplicateKeyError(err) { return "", errors.New("floor already exists in this building") }
+	if err != nil { return "", err }
+	return oidHex(doc.ID), nil
+}
+
+func (r *locationRepoMongo) GetFloor(floorID string) (FloorRow, error) {
+	fid, err := mustOID(floorID); if err != nil { return FloorRow{}, err }
+	var doc floorDoc
+	err = r.d.Collection("floors").FindOne(context.Background(), bson.M{"_id": fid}).Decode(&doc)
+	if err != nil { return FloorRow{}, err }
+	return doc.row(), nil
+}
+
+// ListFloors returns the building's floors from the lowest level up.
+func (r *locationRepoMongo) ListFloors(buildingID string) ([]FloorRow, error) {
+	bid, err := mustOID(buildingID); if err != nil { return nil, err }
+	cur, err := r.d.Collection("floors").Find(context.Background(), bson.M{"building_id": bid},
+		options.Find().SetSort(bson.D{{Key: "level", Value: 1}}))
+	if err != nil { return nil, err }
+	defer cur.Close(context.Background())
+	var out []FloorRow
+	for cur.Next(context.Background()) {
+		var doc floorDoc
+		if err := cur.Decode(&doc); err != nil { return nil, err }
+		out = append(out, doc.row())
+	}
+	return out, cur.Err()
+}
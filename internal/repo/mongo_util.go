@@ -25,3 +25,12 @@ func toIntID(v interface{}) int64 {
 		return 0
 	}
 }
+
+func oids(hexes []string) ([]primitive.ObjectID, error) {
+	out := make([]primitive.ObjectID, 0, len(hexes))
+	for _, h := range hexes {
+		id, err := mustOID(h); if err != nil { return nil, err }
+		out = append(out, id)
+	}
+	return out, nil
+}
@@ -0,0 +1,58 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NotificationRepo is a per-user inbox of things that happened to their
+// bookings without them asking (waitlist promotions and the like).
+type NotificationRepo interface {
+	Create(userID, kind, message string) error
+	ListByUser(userID string, limit int) ([]NotificationRow, error)
+}
+
+type NotificationRow struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type notificationRepoMongo struct{ d *mongo.Database }
+
+func NewNotificationRepoMongo(d *mongo.Database) NotificationRepo { return &notificationRepoMongo{d: d} }
+
+func (r *notificationRepoMongo) Create(userID, kind, message string) error {
+	uid, err := mustOID(userID); if err != nil { return err }
+	_, err = r.d.Collection("notifications").InsertOne(context.Background(), bson.M{
+		"user_id": uid, "kind": kind, "message": message, "created_at": time.Now().UTC(),
+	})
+	return err
+}
+
+// ListByUser returns the newest notifications first.
+func (r *notificationRepoMongo) ListByUser(userID string, limit int) ([]NotificationRow, error) {
+	uid, err := mustOID(userID); if err != nil { return nil, err }
+	cur, err := r.d.Collection("notifications").Find(context.Background(), bson.M{"user_id": uid},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(int64(limit)))
+	if err != nil { return nil, err }
+	defer cur.Close(context.Background())
+	var out []NotificationRow
+	for cur.Next(context.Background()) {
+		var doc struct {
+			ID        primitive.ObjectID `bson:"_id"`
+			Kind      string             `bson:"kind"`
+			Message   string             `bson:"message"`
+			CreatedAt time.Time          `bson:"created_at"`
+		}
+		if err := cur.Decode(&doc); err != nil { return nil, err }
+		out = append(out, NotificationRow{ID: oidHex(doc.ID), Kind: doc.Kind, Message: doc.Message, CreatedAt: doc.CreatedAt})
+	}
+	return out, cur.Err()
+}
@@ -0,0 +1,374 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DateLayout is the format of calendar dates such as effective-from/to.
+const DateLayout = "2006-01-02"
+
+// OpeningHours is a weekly opening-hours template for a room. It applies to
+// the local dates EffectiveFrom through EffectiveTo (inclusive; empty means
+// open-ended). Where templates overlap, the latest EffectiveFrom wins.
+type OpeningHours struct {
+	ID            string       `bson:"-" json:"id"`
+	RoomID        string       `bson:"-" json:"room_id"`
+	EffectiveFrom string       `bson:"effective_from" json:"effective_from"` // YYYY-MM-DD in the room's time zone
+	EffectiveTo   string       `bson:"effective_to,omitempty" json:"effective_to,omitempty"`
+	Ranges        []HoursRange `bson:"ranges" json:"ranges"`
+}
+
+// HoursRange is one opening period on a weekday, as local wall-clock times.
+type HoursRange struct {
+	Weekday time.Weekday `bson:"weekday" json:"weekday"` // 0 = Sunday
+	Open    string       `bson:"open" json:"open"`       // "hh:mm"
+	Close   string       `bson:"close" json:"close"`     // "hh:mm", "24:00" for midnight
+}
+
+// Minutes returns the range as minutes after local midnight.
+func (h HoursRange) Minutes() (open, close int, err error) {
+	if open, err = clockMinutes(h.Open); err != nil { return 0, 0, err }
+	if close, err = clockMinutes(h.Close); err != nil { return 0, 0, err }
+	return open, close, nil
+}
+
+func clockMinutes(v string) (int, error) {
+	var hh, mm int
+	if n, _ := fmt.Sscanf(v, "%d:%d", &hh, &mm); n != 2 || len(v) != 5 { return 0, fmt.Errorf("invalid time of day %q", v) }
+	if hh < 0 || mm < 0 || mm > 59 || hh*60+mm > 24*60 { return 0, fmt.Errorf("invalid time of day %q", v) }
+	return hh*60 + mm, nil
+}
+
+// in reports whether the template applies on the local date day (YYYY-MM-DD).
+func (h OpeningHours) in(day string) bool {
+	return h.EffectiveFrom <= day && (h.EffectiveTo == "" || day <= h.EffectiveTo)
+}
+
+type hoursDoc struct {
+	ID           primitive.ObjectID `bson:"_id"`
+	RoomID       primitive.ObjectID `bson:"room_id"`
+	OpeningHours `bson:",inline"`
+}
+
+func (r *roomRepoMongo) AddHours(h OpeningHours) (string, error) {
+	oid, err := mustOID(h.RoomID); if err != nil { return "", err }
+	doc := hoursDoc{ID: primitive.NewObjectID(), RoomID: oid, OpeningHours: h}
+	if _, err := r.d.Collection("room_hours").InsertOne(context.Background(), doc); err != nil { return "", err }
+	return oidHex(doc.ID), nil
+}
+
+func (r *roomRepoMongo) ListHours(roomID string) ([]OpeningHours, error) {
+	oid, err := mustOID(roomID); if err != nil { return nil, err }
+	return r.listHours(context.Background(), oid)
+}
+
+func (r *roomRepoMongo) DeleteHours(roomID, hoursID string) error {
+	oid, err := mustOID(roomID); if err != nil { return err }
+	hid, err := mustOID(hoursID); if err != nil { return err }
+	res, err := r.d.Collection("room_hours").DeleteOne(context.Background(), bson.M{"_id": hid, "room_id": oid})
+	if err != nil { return err }
+	if res.DeletedCount == 0 { return errors.New("opening hours not found") }
+	return nil
+}
+
+// listHours returns the room's templates, latest effective_from first.
+func (r *roomRepoMongo) listHours(ctx context.Context, roomID primitive.ObjectID) ([]OpeningHours, error) {
+	cur, err := r.d.Collection("room_hours").Find(ctx, bson.M{"room_id": roomID},
+		options.Find().SetSort(bson.D{{Key: "effective_from", Value: -1}}))
+	if err != nil { return nil, err }
+	defer cur.Close(ctx)
+	var out []OpeningHours
+	for cur.Next(ctx) {
+		var doc hoursDoc
+		if err := cur.Decode(&doc); err != nil { return nil, err }
+		h := doc.OpeningHours
+		h.ID, h.RoomID = oidHex(doc.ID), oidHex(doc.RoomID)
+		out = append(out, h)
+	}
+	return out, cur.Err()
+}
+
+type span struct{ start, end time.Time }
+
+// Period is a stretch of a room's time line. Reason names the holiday for
+// holiday closures.
+type Period struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// isOpen reports whether [start, end) is covered by the room's open time:
+// one-off open windows from SetSchedule layered over the weekly hours in
+// effect on each local day. Adjacent periods join up, so a booking may run
+// from a weekly range into a one-off extension. A room without hours of its
+// own follows its building's. Any closed window or holiday touching the
+// interval wins over all of them.
+func (r *roomRepoMongo) isOpen(ctx context.Context, room roomDoc, start, end time.Time) (bool, error) {
+	od, err := r.loadOpenData(ctx, []roomDoc{room}, start, end)
+	if err != nil { return false, err }
+	return od.isOpen(room, start, end), nil
+}
+
+// openData is what the open-time rules read for a set of rooms over one
+// interval, loaded with a fixed number of queries however many rooms there
+// are.
+type openData struct {
+	windows   map[primitive.ObjectID][]windowDoc
+	holidays  []holidayDoc
+	hours     map[primitive.ObjectID][]OpeningHours // latest effective_from first
+	buildings map[primitive.ObjectID][]HoursRange
+}
+
+type windowDoc struct {
+	RoomID primitive.ObjectID `bson:"room_id"`
+	Start  time.Time          `bson:"start_at"`
+	End    time.Time          `bson:"end_at"`
+	IsOpen bool               `bson:"is_open"`
+}
+
+type holidayDoc struct {
+	Date       string             `bson:"date"`
+	Name       string             `bson:"name"`
+	BuildingID primitive.ObjectID `bson:"building_id,omitempty"`
+}
+
+func (r *roomRepoMongo) loadOpenData(ctx context.Context, rooms []roomDoc, start, end time.Time) (*openData, error) {
+	od := &openData{
+		windows:   map[primitive.ObjectID][]windowDoc{},
+		hours:     map[primitive.ObjectID][]OpeningHours{},
+		buildings: map[primitive.ObjectID][]HoursRange{},
+	}
+	if len(rooms) == 0 { return od, nil }
+	ids := make([]primitive.ObjectID, len(rooms))
+	var days []string
+	seenZone, seenDay := map[string]bool{}, map[string]bool{}
+	for i, room := range rooms {
+		ids[i] = room.ID
+		loc := room.row().Location()
+		if seenZone[loc.String()] { continue }
+		seenZone[loc.String()] = true
+		for _, d := range localDays(loc, start, end) {
+			if !seenDay[d] { seenDay[d], days = true, append(days, d) }
+		}
+	}
+
+	cur, err := r.d.Collection("room_schedules").Find(ctx, bson.M{
+		"room_id":  bson.M{"$in": ids},
+		"start_at": bson.M{"$lt": end.UTC()},
+		"end_at":   bson.M{"$gt": start.UTC()},
+	})
+	if err != nil { return nil, err }
+	var windows []windowDoc
+	if err := cur.All(ctx, &windows); err != nil { return nil, err }
+	for _, w := range windows { od.windows[w.RoomID] = append(od.windows[w.RoomID], w) }
+
+	cur, err = r.d.Collection("holidays").Find(ctx, bson.M{"date": bson.M{"$in": days}})
+	if err != nil { return nil, err }
+	if err := cur.All(ctx, &od.holidays); err != nil { return nil, err }
+
+	cur, err = r.d.Collection("room_hours").Find(ctx, bson.M{"room_id": bson.M{"$in": ids}},
+		options.Find().SetSort(bson.D{{Key: "effective_from", Value: -1}}))
+	if err != nil { return nil, err }
+	var hours []hoursDoc
+	if err := cur.All(ctx, &hours); err != nil { return nil, err }
+	for _, h := range hours { od.hours[h.RoomID] = append(od.hours[h.RoomID], h.OpeningHours) }
+
+	// rooms without hours of their own follow their building's
+	var bids []primitive.ObjectID
+	seenBuilding := map[primitive.ObjectID]bool{}
+	for _, room := range rooms {
+		if len(od.hours[room.ID]) > 0 || room.BuildingID.IsZero() || seenBuilding[room.BuildingID] { continue }
+		seenBuilding[room.BuildingID] = true
+		bids = append(bids, room.BuildingID)
+	}
+	if len(bids) == 0 { return od, nil }
+	cur, err = r.d.Collection("buildings").Find(ctx, bson.M{"_id": bson.M{"$in": bids}},
+		options.Find().SetProjection(bson.M{"hours": 1}))
+	if err != nil { return nil, err }
+	var buildings []buildingDoc
+	if err := cur.All(ctx, &buildings); err != nil { return nil, err }
+	for _, b := range buildings { od.buildings[b.ID] = b.Hours }
+	return od, nil
+}
+
+// openTime collects the periods that make the room open, and the closed
+// windows and holidays that override them, for the local days touched by
+// [start, end). Neither list is merged or clipped. [start, end) must lie
+// within the interval the data was loaded for.
+func (od *openData) openTime(room roomDoc, start, end time.Time) ([]span, []Period) {
+	loc := room.row().Location()
+	var open []span
+	var closed []Period
+	for _, w := range od.windows[room.ID] {
+		if !w.End.After(start) || !w.Start.Before(end) { continue }
+		if w.IsOpen {
+			open = append(open, span{w.Start, w.End})
+		} else {
+			closed = append(closed, Period{Start: w.Start, End: w.End})
+		}
+	}
+	days := map[string]bool{}
+	for _, d := range localDays(loc, start, end) { days[d] = true }
+	for _, h := range od.holidays {
+		if !days[h.Date] || (!h.BuildingID.IsZero() && h.BuildingID != room.BuildingID) { continue }
+		day, err := time.ParseInLocation(DateLayout, h.Date, loc)
+		if err != nil { continue }
+		closed = append(closed, Period{Start: day, End: day.AddDate(0, 0, 1), Reason: h.Name})
+	}
+
+	hours := od.hours[room.ID]
+	if len(hours) == 0 {
+		if bh := od.buildings[room.BuildingID]; len(bh) > 0 { hours = []OpeningHours{{EffectiveFrom: "0000-01-01", Ranges: bh}} }
+	}
+	open = append(open, weeklySpans(hours, loc, start, end)...)
+	return open, closed
+}
+
+// isOpen is roomRepoMongo.isOpen over the loaded data.
+func (od *openData) isOpen(room roomDoc, start, end time.Time) bool {
+	open, closed := od.openTime(room, start, end)
+	return len(closed) == 0 && covers(open, start, end)
+}
+
+// OpenTime returns the room's open periods within [start, end), merged and
+// with closures cut out, and the closed windows and holidays in the range.
+// Both are clipped to the range and given in the room's time zone.
+func (r *roomRepoMongo) OpenTime(roomID string, start, end time.Time) ([]Period, []Period, error) {
+	ctx := context.Background()
+	doc, err := r.getDoc(ctx, roomID)
+	if err != nil { return nil, nil, err }
+	od, err := r.loadOpenData(ctx, []roomDoc{doc}, start, end)
+	if err != nil { return nil, nil, err }
+	open, closed := od.periods(doc, start, end)
+	return open, closed, nil
+}
+
+// RoomOpenTime is one room's result from OpenTimes.
+type RoomOpenTime struct {
+	Room   RoomRow
+	Open   []Period
+	Closed []Period
+}
+
+// OpenTimes is OpenTime for each of roomIDs, in that order, over the same
+// [start, end). It takes a fixed number of queries however many rooms are
+// asked for.
+func (r *roomRepoMongo) OpenTimes(roomIDs []string, start, end time.Time) ([]RoomOpenTime, error) {
+	ctx := context.Background()
+	ids, err := oids(roomIDs); if err != nil { return nil, err }
+	cur, err := r.d.Collection("rooms").Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil { return nil, err }
+	var docs []roomDoc
+	if err := cur.All(ctx, &docs); err != nil { return nil, err }
+	if err := r.withZones(ctx, docs); err != nil { return nil, err }
+	od, err := r.loadOpenData(ctx, docs, start, end)
+	if err != nil { return nil, err }
+	byID := map[primitive.ObjectID]roomDoc{}
+	for _, d := range docs { byID[d.ID] = d }
+	out := make([]RoomOpenTime, 0, len(ids))
+	for i, id := range ids {
+		doc, ok := byID[id]
+		if !ok { return nil, fmt.Errorf("room %s not found", roomIDs[i]) }
+		open, closed := od.periods(doc, start, end)
+		out = append(out, RoomOpenTime{Room: doc.row(), Open: open, Closed: closed})
+	}
+	return out, nil
+}
+
+// periods is OpenTime over the loaded data.
+func (od *openData) periods(doc roomDoc, start, end time.Time) ([]Period, []Period) {
+	spans, closed := od.openTime(doc, start, end)
+	loc := doc.row().Location()
+
+	var cut []span
+	for _, c := range closed { cut = append(cut, span{c.Start, c.End}) }
+	var open []Period
+	for _, sp := range subtract(merge(spans), merge(cut)) {
+		if sp.start.Before(start) { sp.start = start }
+		if sp.end.After(end) { sp.end = end }
+		if sp.end.After(sp.start) { open = append(open, Period{Start: sp.start.In(loc), End: sp.end.In(loc)}) }
+	}
+	var out []Period
+	for _, c := range closed {
+		if c.Start.Before(start) { c.Start = start }
+		if c.End.After(end) { c.End = end }
+		if !c.End.After(c.Start) { continue }
+		c.Start, c.End = c.Start.In(loc), c.End.In(loc)
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return open, out
+}
+
+// merge sorts spans and joins overlapping or adjacent ones.
+func merge(spans []span) []span {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start.Before(spans[j].start) })
+	var out []span
+	for _, sp := range spans {
+		if n := len(out); n > 0 && !sp.start.After(out[n-1].end) {
+			if sp.end.After(out[n-1].end) { out[n-1].end = sp.end }
+			continue
+		}
+		out = append(out, sp)
+	}
+	return out
+}
+
+// subtract removes the merged spans cut from the merged spans from.
+func subtract(from, cut []span) []span {
+	var out []span
+	for _, sp := range from {
+		for _, c := range cut {
+			if !c.end.After(sp.start) || !c.start.Before(sp.end) { continue }
+			if c.start.After(sp.start) { out = append(out, span{sp.start, c.start}) }
+			sp.start = c.end
+			if !sp.end.After(sp.start) { break }
+		}
+		if sp.end.After(sp.start) { out = append(out, sp) }
+	}
+	return out
+}
+
+// weeklySpans expands the templates into concrete periods for every local
+// day touched by [start, end).
+func weeklySpans(hours []OpeningHours, loc *time.Location, start, end time.Time) []span {
+	if len(hours) == 0 { return nil }
+	var out []span
+	ls := start.In(loc)
+	for day := time.Date(ls.Year(), ls.Month(), ls.Day(), 0, 0, 0, 0, loc); day.Before(end); day = day.AddDate(0, 0, 1) {
+		key := day.Format(DateLayout)
+		for _, h := range hours {
+			if !h.in(key) { continue }
+			for _, rg := range h.Ranges {
+				if rg.Weekday != day.Weekday() { continue }
+				o, c, err := rg.Minutes()
+				if err != nil { continue }
+				y, m, d := day.Date()
+				out = append(out, span{time.Date(y, m, d, 0, o, 0, 0, loc), time.Date(y, m, d, 0, c, 0, 0, loc)})
+			}
+			break // only the latest template in effect counts
+		}
+	}
+	return out
+}
+
+// covers reports whether the union of spans contains [start, end).
+func covers(spans []span, start, end time.Time) bool {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start.Before(spans[j].start) })
+	at := start
+	for _, sp := range spans {
+		if sp.start.After(at) { return false }
+		if sp.end.After(at) { at = sp.end }
+		if !at.Before(end) { return true }
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package repo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func everyDay(open, close string) []OpeningHours {
+	h := OpeningHours{EffectiveFrom: "2000-01-01"}
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		h.Ranges = append(h.Ranges, HoursRange{Weekday: wd, Open: open, Close: close})
+	}
+	return []OpeningHours{h}
+}
+
+func TestWeeklySpansAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utc := func(v string) time.Time {
+		t.Helper()
+		ts, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	tests := []struct {
+		name        string
+		open, close string
+		day         string // local date
+		wantStart   string // UTC
+		wantLen     time.Duration
+	}{
+		{"daytime before spring forward", "08:00", "20:00", "2026-03-28", "2026-03-28T07:00:00Z", 12 * time.Hour},
+		{"daytime on spring-forward day", "08:00", "20:00", "2026-03-29", "2026-03-29T06:00:00Z", 12 * time.Hour},
+		{"whole spring-forward day", "00:00", "24:00", "2026-03-29", "2026-03-28T23:00:00Z", 23 * time.Hour},
+		{"night range across the gap", "01:00", "04:00", "2026-03-29", "2026-03-29T00:00:00Z", 2 * time.Hour},
+		{"daytime on fall-back day", "08:00", "20:00", "2026-10-25", "2026-10-25T07:00:00Z", 12 * time.Hour},
+		{"whole fall-back day", "00:00", "24:00", "2026-10-25", "2026-10-24T22:00:00Z", 25 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			day, err := time.ParseInLocation(DateLayout, tt.day, berlin)
+			if err != nil {
+				t.Fatal(err)
+			}
+			spans := weeklySpans(everyDay(tt.open, tt.close), berlin, day, day.AddDate(0, 0, 1))
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1: %v", len(spans), spans)
+			}
+			if want := utc(tt.wantStart); !spans[0].start.Equal(want) {
+				t.Errorf("start = %s, want %s", spans[0].start.UTC().Format(time.RFC3339), tt.wantStart)
+			}
+			if got := spans[0].end.Sub(spans[0].start); got != tt.wantLen {
+				t.Errorf("length = %s, want %s", got, tt.wantLen)
+			}
+		})
+	}
+
+	// a booking spanning the fall-back night is covered by two whole days
+	start, end := utc("2026-10-24T20:00:00Z"), utc("2026-10-25T05:00:00Z")
+	if !covers(weeklySpans(everyDay("00:00", "24:00"), berlin, start, end), start, end) {
+		t.Error("round-the-clock hours do not cover a booking across the fall-back night")
+	}
+}
+
+func TestLocalDays(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(v string) time.Time {
+		ts, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	tests := []struct {
+		name       string
+		loc        *time.Location
+		start, end string
+		want       []string
+	}{
+		{"one local day in UTC terms", berlin, "2026-03-28T23:00:00Z", "2026-03-29T22:00:00Z", []string{"2026-03-29"}},
+		{"short day then the next", berlin, "2026-03-28T23:00:00Z", "2026-03-29T22:00:01Z", []string{"2026-03-29", "2026-03-30"}},
+		{"long fall-back day", berlin, "2026-10-24T22:00:00Z", "2026-10-25T23:00:00Z", []string{"2026-10-25"}},
+		{"UTC evening is the previous local day", newYork, "2026-03-08T03:00:00Z", "2026-03-08T05:00:00Z", []string{"2026-03-07"}},
+		{"across the US change", newYork, "2026-03-07T12:00:00Z", "2026-03-09T12:00:00Z", []string{"2026-03-07", "2026-03-08", "2026-03-09"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := localDays(tt.loc, at(tt.start), at(tt.end)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("localDays = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -13,114 +15,459 @@ import (
 
 
 type RoomRepo interface {
-	Create(name string, capacity int) (id string, err error)
+	Create(name string, capacity int, timeZone, buildingID, floorID string) (id string, err error)
 	List() ([]RoomRow, error)
-	SetSchedule(roomID string, start, end string, isOpen bool) error
-	IsWithinOpenSchedule(roomID string, start, end string) (bool, error)
-	FindAvailable(minCapacity int, start, end string) ([]RoomRow, error)
+	SetSchedule(roomID string, start, end time.Time, isOpen bool) (windowID string, err error)
+	ListSchedule(roomID string) ([]ScheduleWindow, error)
+	GetSchedule(windowID string) (ScheduleWindow, error)
+	UpdateSchedule(windowID string, start, end time.Time) error
+	DeleteSchedule(windowID string) error
+	HasScheduleOverlap(roomID string, start, end time.Time, isOpen bool, excludeID string) (bool, error)
+	IsWithinOpenSchedule(roomID string, start, end time.Time) (bool, error)
+	OpenTime(roomID string, start, end time.Time) (open, closed []Period, err error)
+	OpenTimes(roomIDs []string, start, end time.Time) ([]RoomOpenTime, error)
+	FindAvailable(f RoomFilter, start, end time.Time) ([]RoomRow, error)
+	FindMatching(f RoomFilter) ([]RoomRow, error)
+	GetByID(roomID string) (RoomRow, error)
+	SetPolicy(roomID string, p RoomPolicy) error
+	Update(roomID string, u RoomUpdate, mask []string) error
+	SetArchived(roomID string, archived bool) error
+	SetAmenities(roomID string, keys []string) error
+	Delete(roomID string) error
+	AddHours(h OpeningHours) (id string, err error)
+	ListHours(roomID string) ([]OpeningHours, error)
+	DeleteHours(roomID, hoursID string) error
 }
 
 type RoomRow struct {
-	ID       string
-	Name     string
-	Capacity int
+	ID         string
+	Name       string
+	Capacity   int
+	Policy     RoomPolicy
+	TimeZone   string   // IANA name in effect: the room's own, else its building's; empty means UTC
+	Archived   bool     // hidden from search and closed to new bookings
+	Amenities  []string // keys from the amenity catalog
+	BuildingID string   // empty while the room is not placed in a building
+	FloorID    string
+}
+
+// RoomFilter narrows FindAvailable; zero fields match every room. Any-room
+// waitlist entries store one as well.
+type RoomFilter struct {
+	MinCapacity int      `json:"min_capacity,omitempty"`
+	Amenities   []string `json:"amenities,omitempty"` // the room must have all of these
+	BuildingID  string   `json:"building_id,omitempty"`
+	FloorID     string   `json:"floor_id,omitempty"`
+}
+
+// RoomUpdate carries the new values for Update; only the fields named in
+// the mask ("name", "capacity", "time_zone", "building_id", "floor_id") are
+// written. An empty building or floor takes the room out of it.
+type RoomUpdate struct {
+	Name       string
+	Capacity   int
+	TimeZone   string
+	BuildingID string
+	FloorID    string
+}
+
+// roomMaskFields maps update-mask names to document fields.
+var roomMaskFields = map[string]string{
+	"name": "name", "capacity": "capacity", "time_zone": "time_zone",
+	"building_id": "building_id", "floor_id": "floor_id",
+}
+
+// zones caches loaded time zones by IANA name.
+var zones sync.Map
+
+// LoadZone is time.LoadLocation with a cache; "" is UTC. Zones are checked
+// with it before they are stored.
+func LoadZone(name string) (*time.Location, error) {
+	if name == "" { return time.UTC, nil }
+	if loc, ok := zones.Load(name); ok { return loc.(*time.Location), nil }
+	loc, err := time.LoadLocation(name)
+	if err != nil { return nil, fmt.Errorf("unknown time zone %q", name) }
+	zones.Store(name, loc)
+	return loc, nil
+}
+
+// Location returns the room's time zone. Stored zones are validated on
+// write, so only a document edited by hand can fall back to UTC here.
+func (r RoomRow) Location() *time.Location {
+	loc, err := LoadZone(r.TimeZone)
+	if err != nil { return time.UTC }
+	return loc
+}
+
+// ScheduleWindow is a one-off period in which a room is open, or closed
+// regardless of its other opening times.
+type ScheduleWindow struct {
+	ID     string    `json:"id"`
+	RoomID string    `json:"room_id"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	IsOpen bool      `json:"is_open"`
+}
+
+type scheduleDoc struct {
+	ID     primitive.ObjectID `bson:"_id"`
+	RoomID primitive.ObjectID `bson:"room_id"`
+	Start  time.Time          `bson:"start_at"`
+	End    time.Time          `bson:"end_at"`
+	IsOpen bool               `bson:"is_open"`
+}
+
+func (d scheduleDoc) row() ScheduleWindow {
+	return ScheduleWindow{ID: oidHex(d.ID), RoomID: oidHex(d.RoomID), Start: d.Start, End: d.End, IsOpen: d.IsOpen}
+}
+
+// RoomPolicy restricts which intervals may be booked in a room. Zero fields
+// mean no restriction.
+type RoomPolicy struct {
+	MinDurationMin     int `bson:"min_duration_min" json:"min_duration_min"`
+	MaxDurationMin     int `bson:"max_duration_min" json:"max_duration_min"`
+	MaxAdvanceDays     int `bson:"max_advance_days" json:"max_advance_days"`         // how far ahead a booking may start
+	MinNoticeMin       int `bson:"min_notice_min" json:"min_notice_min"`             // how soon before start a booking must be made
+	SlotGranularityMin int `bson:"slot_granularity_min" json:"slot_granularity_min"` // starts must fall on multiples of this
+	BufferBeforeMin    int `bson:"buffer_before_min" json:"buffer_before_min"`       // setup time kept free before each booking
+	BufferAfterMin     int `bson:"buffer_after_min" json:"buffer_after_min"`         // cleanup time kept free after each booking
+	MinOccupancyPct    int `bson:"min_occupancy_pct" json:"min_occupancy_pct"`       // smallest party, as a percentage of capacity
+
+	WaitlistMode string `bson:"waitlist_mode" json:"waitlist_mode"` // "offer" (default) or "auto"
+}
+
+// Pad widens [start, end) by the room's combined buffer on both sides, so a
+// plain overlap check against the room's other bookings also keeps the setup
+// and cleanup time between them free.
+func (p RoomPolicy) Pad(start, end time.Time) (time.Time, time.Time) {
+	b := time.Duration(p.BufferBeforeMin+p.BufferAfterMin) * time.Minute
+	return start.Add(-b), end.Add(b)
+}
+
+type roomDoc struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	Name       string             `bson:"name"`
+	Capacity   int                `bson:"capacity"`
+	Policy     RoomPolicy         `bson:"policy"`
+	TimeZone   string             `bson:"time_zone,omitempty"`
+	Archived   bool               `bson:"archived,omitempty"`
+	Amenities  []string           `bson:"amenities,omitempty"`
+	BuildingID primitive.ObjectID `bson:"building_id,omitempty"`
+	FloorID    primitive.ObjectID `bson:"floor_id,omitempty"`
+
+	buildingZone string // filled in by withZones
+}
+
+func (d roomDoc) row() RoomRow {
+	out := RoomRow{ID: oidHex(d.ID), Name: d.Name, Capacity: d.Capacity, Policy: d.Policy, TimeZone: d.TimeZone, Archived: d.Archived, Amenities: d.Amenities}
+	if out.TimeZone == "" { out.TimeZone = d.buildingZone }
+	if !d.BuildingID.IsZero() { out.BuildingID = oidHex(d.BuildingID) }
+	if !d.FloorID.IsZero() { out.FloorID = oidHex(d.FloorID) }
+	return out
 }
 
 type roomRepoMongo struct{ d *mongo.Database }
 
 func NewRoomRepoMongo(d *mongo.Database) RoomRepo { return &roomRepoMongo{d: d} }
 
-func (r *roomRepoMongo) Create(name string, capacity int) (string, error) {
-	res, err := r.d.Collection("rooms").InsertOne(context.Background(), bson.M{
-		"name": name, "capacity": capacity,
-	})
+func (r *roomRepoMongo) Create(name string, capacity int, timeZone, buildingID, floorID string) (string, error) {
+	doc := bson.M{"name": name, "capacity": capacity, "time_zone": timeZone}
+	for field, hex := range map[string]string{"building_id": buildingID, "floor_id": floorID} {
+		if hex == "" { continue }
+		id, err := mustOID(hex); if err != nil { return "", err }
+		doc[field] = id
+	}
+	res, err := r.d.Collection("rooms").InsertOne(context.Background(), doc)
+	if mongo.IsDuplicateKeyError(err) { return "", errors.New("a room with this name already exists in the building") }
 	if err != nil { return "", err }
 	return oidHex(res.InsertedID.(primitive.ObjectID)), nil
 }
 
 func (r *roomRepoMongo) List() ([]RoomRow, error) {
-	cur, err := r.d.Collection("rooms").Find(context.Background(), bson.M{})
+	return r.findRows(context.Background(), bson.M{}, nil)
+}
+
+// findRows returns the rooms matching filter with their zones resolved.
+func (r *roomRepoMongo) findRows(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]RoomRow, error) {
+	cur, err := r.d.Collection("rooms").Find(ctx, filter, opts)
 	if err != nil { return nil, err }
-	defer cur.Close(context.Background())
+	var docs []roomDoc
+	if err := cur.All(ctx, &docs); err != nil { return nil, err }
+	if err := r.withZones(ctx, docs); err != nil { return nil, err }
 	var out []RoomRow
-	for cur.Next(context.Background()) {
-		var doc struct {
-			ID       primitive.ObjectID `bson:"_id"`
-			Name     string             `bson:"name"`
-			Capacity int                `bson:"capacity"`
+	for _, doc := range docs { out = append(out, doc.row()) }
+	return out, nil
+}
+
+// getDoc loads one room with its zone resolved.
+func (r *roomRepoMongo) getDoc(ctx context.Context, roomID string) (roomDoc, error) {
+	oid, err := mustOID(roomID); if err != nil { return roomDoc{}, err }
+	var doc roomDoc
+	if err := r.d.Collection("rooms").FindOne(ctx, bson.M{"_id": oid}).Decode(&doc); err != nil { return roomDoc{}, err }
+	docs := []roomDoc{doc}
+	if err := r.withZones(ctx, docs); err != nil { return roomDoc{}, err }
+	return docs[0], nil
+}
+
+// withZones gives rooms without a zone of their own their building's, in
+// one query for all of them.
+func (r *roomRepoMongo) withZones(ctx context.Context, docs []roomDoc) error {
+	var bids []primitive.ObjectID
+	seen := map[primitive.ObjectID]bool{}
+	for _, d := range docs {
+		if d.TimeZone != "" || d.BuildingID.IsZero() || seen[d.BuildingID] { continue }
+		seen[d.BuildingID] = true
+		bids = append(bids, d.BuildingID)
+	}
+	if len(bids) == 0 { return nil }
+	cur, err := r.d.Collection("buildings").Find(ctx, bson.M{"_id": bson.M{"$in": bids}},
+		options.Find().SetProjection(bson.M{"time_zone": 1}))
+	if err != nil { return err }
+	var buildings []buildingDoc
+	if err := cur.All(ctx, &buildings); err != nil { return err }
+	zone := map[primitive.ObjectID]string{}
+	for _, b := range buildings { zone[b.ID] = b.TimeZone }
+	for i := range docs {
+		if docs[i].TimeZone == "" { docs[i].buildingZone = zone[docs[i].BuildingID] }
+	}
+	return nil
+}
+
+// FindMatching returns the rooms in service that match rf, whatever their
+// schedule, ordered by name.
+func (r *roomRepoMongo) FindMatching(rf RoomFilter) ([]RoomRow, error) {
+	q, err := rf.query()
+	if err != nil { return nil, err }
+	return r.findRows(context.Background(), q, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+}
+
+// query selects the unarchived rooms matching the filter.
+func (rf RoomFilter) query() (bson.M, error) {
+	q := bson.M{
+		"capacity": bson.M{"$gte": rf.MinCapacity},
+		"archived": bson.M{"$ne": true},
+	}
+	if len(rf.Amenities) > 0 { q["amenities"] = bson.M{"$all": rf.Amenities} }
+	for field, hex := range map[string]string{"building_id": rf.BuildingID, "floor_id": rf.FloorID} {
+		if hex == "" { continue }
+		id, err := mustOID(hex); if err != nil { return nil, err }
+		q[field] = id
+	}
+	return q, nil
+}
+
+func (r *roomRepoMongo) GetByID(roomID string) (RoomRow, error) {
+	doc, err := r.getDoc(context.Background(), roomID)
+	if err != nil { return RoomRow{}, err }
+	return doc.row(), nil
+}
+
+func (r *roomRepoMongo) SetPolicy(roomID string, p RoomPolicy) error {
+	oid, err := mustOID(roomID); if err != nil { return err }
+	res, err := r.d.Collection("rooms").UpdateOne(context.Background(),
+		bson.M{"_id": oid}, bson.M{"$set": bson.M{"policy": p}})
+	if err != nil { return err }
+	if res.MatchedCount == 0 { return mongo.ErrNoDocuments }
+	return nil
+}
+
+func (r *roomRepoMongo) Update(roomID string, u RoomUpdate, mask []string) error {
+	oid, err := mustOID(roomID); if err != nil { return err }
+	values := map[string]interface{}{
+		"name": u.Name, "capacity": u.Capacity, "time_zone": u.TimeZone,
+		"building_id": u.BuildingID, "floor_id": u.FloorID,
+	}
+	set, unset := bson.M{}, bson.M{}
+	for _, m := range mask {
+		field, ok := roomMaskFields[m]
+		if !ok { return fmt.Errorf("unknown field %q in update mask", m) }
+		switch {
+		case field != "building_id" && field != "floor_id":
+			set[field] = values[m]
+		case values[m] == "":
+			unset[field] = ""
+		default:
+			id, err := mustOID(values[m].(string)); if err != nil { return err }
+			set[field] = id
 		}
-		if err := cur.Decode(&doc); err != nil { return nil, err }
-		out = append(out, RoomRow{ID: oidHex(doc.ID), Name: doc.Name, Capacity: doc.Capacity})
 	}
-	return out, cur.Err()
+	if len(set) == 0 && len(unset) == 0 { return errors.New("empty update mask") }
+	upd := bson.M{}
+	if len(set) > 0 { upd["$set"] = set }
+	if len(unset) > 0 { upd["$unset"] = unset }
+	res, err := r.d.Collection("rooms").UpdateOne(context.Background(), bson.M{"_id": oid}, upd)
+	if mongo.IsDuplicateKeyError(err) { return errors.New("a room with this name already exists in the building") }
+	if err != nil { return err }
+	if res.MatchedCount == 0 { return mongo.ErrNoDocuments }
+	return nil
 }
 
-func (r *roomRepoMongo) SetSchedule(roomID string, start, end string, isOpen bool) error {
+func (r *roomRepoMongo) SetAmenities(roomID string, keys []string) error {
 	oid, err := mustOID(roomID); if err != nil { return err }
-	_, err = r.d.Collection("room_schedules").InsertOne(context.Background(), bson.M{
-		"room_id": oid, "start_at": start, "end_at": end, "is_open": isOpen,
-	})
+	if keys == nil { keys = []string{} }
+	res, err := r.d.Collection("rooms").UpdateOne(context.Background(),
+		bson.M{"_id": oid}, bson.M{"$set": bson.M{"amenities": keys}})
+	if err != nil { return err }
+	if res.MatchedCount == 0 { return mongo.ErrNoDocuments }
+	return nil
+}
+
+func (r *roomRepoMongo) SetArchived(roomID string, archived bool) error {
+	oid, err := mustOID(roomID); if err != nil { return err }
+	res, err := r.d.Collection("rooms").UpdateOne(context.Background(),
+		bson.M{"_id": oid}, bson.M{"$set": bson.M{"archived": archived}})
+	if err != nil { return err }
+	if res.MatchedCount == 0 { return mongo.ErrNoDocuments }
+	return nil
+}
+
+// Delete removes the room together with its schedule windows, opening hours,
+// waitlist entries and places in favorites. Callers make sure no bookings
+// refer to it.
+func (r *roomRepoMongo) Delete(roomID string) error {
+	oid, err := mustOID(roomID); if err != nil { return err }
+	ctx := context.Background()
+	res, err := r.d.Collection("rooms").DeleteOne(ctx, bson.M{"_id": oid})
+	if err != nil { return err }
+	if res.DeletedCount == 0 { return mongo.ErrNoDocuments }
+	for _, coll := range []string{"room_schedules", "room_hours", "waitlist"} {
+		if _, err := r.d.Collection(coll).DeleteMany(ctx, bson.M{"room_id": oid}); err != nil { return err }
+	}
+	_, err = r.d.Collection("users").UpdateMany(ctx, bson.M{"favorite_room_ids": oid}, bson.M{"$pull": bson.M{"favorite_room_ids": oid}})
 	return err
 }
 
-func (r *roomRepoMongo) IsWithinOpenSchedule(roomID string, start, end string) (bool, error) {
-	oid, err := mustOID(roomID); if err != nil { return false, err }
-	cnt, err := r.d.Collection("room_schedules").CountDocuments(context.Background(), bson.M{
-		"room_id": oid, "is_open": true,
-		"start_at": bson.M{"$lte": start},
-		"end_at":   bson.M{"$gte": end},
+func (r *roomRepoMongo) SetSchedule(roomID string, start, end time.Time, isOpen bool) (string, error) {
+	oid, err := mustOID(roomID); if err != nil { return "", err }
+	res, err := r.d.Collection("room_schedules").InsertOne(context.Background(), bson.M{
+		"room_id": oid, "start_at": start.UTC(), "end_at": end.UTC(), "is_open": isOpen,
 	})
+	if err != nil { return "", err }
+	return oidHex(res.InsertedID.(primitive.ObjectID)), nil
+}
+
+// ListSchedule returns the room's windows ordered by start.
+func (r *roomRepoMongo) ListSchedule(roomID string) ([]ScheduleWindow, error) {
+	oid, err := mustOID(roomID); if err != nil { return nil, err }
+	cur, err := r.d.Collection("room_schedules").Find(context.Background(), bson.M{"room_id": oid},
+		options.Find().SetSort(bson.D{{Key: "start_at", Value: 1}}))
+	if err != nil { return nil, err }
+	defer cur.Close(context.Background())
+	var out []ScheduleWindow
+	for cur.Next(context.Background()) {
+		var doc scheduleDoc
+		if err := cur.Decode(&doc); err != nil { return nil, err }
+		out = append(out, doc.row())
+	}
+	return out, cur.Err()
+}
+
+func (r *roomRepoMongo) GetSchedule(windowID string) (ScheduleWindow, error) {
+	wid, err := mustOID(windowID); if err != nil { return ScheduleWindow{}, err }
+	var doc scheduleDoc
+	err = r.d.Collection("room_schedules").FindOne(context.Background(), bson.M{"_id": wid}).Decode(&doc)
+	if err != nil { return ScheduleWindow{}, err }
+	return doc.row(), nil
+}
+
+func (r *roomRepoMongo) UpdateSchedule(windowID string, start, end time.Time) error {
+	wid, err := mustOID(windowID); if err != nil { return err }
+	res, err := r.d.Collection("room_schedules").UpdateOne(context.Background(),
+		bson.M{"_id": wid}, bson.M{"$set": bson.M{"start_at": start.UTC(), "end_at": end.UTC()}})
+	if err != nil { return err }
+	if res.MatchedCount == 0 { return mongo.ErrNoDocuments }
+	return nil
+}
+
+func (r *roomRepoMongo) DeleteSchedule(windowID string) error {
+	wid, err := mustOID(windowID); if err != nil { return err }
+	res, err := r.d.Collection("room_schedules").DeleteOne(context.Background(), bson.M{"_id": wid})
+	if err != nil { return err }
+	if res.DeletedCount == 0 { return mongo.ErrNoDocuments }
+	return nil
+}
+
+// HasScheduleOverlap reports whether another window of the same kind
+// overlaps [start, end). Open and closed windows may overlap; that is how a
+// closure is cut out of open time.
+func (r *roomRepoMongo) HasScheduleOverlap(roomID string, start, end time.Time, isOpen bool, excludeID string) (bool, error) {
+	oid, err := mustOID(roomID); if err != nil { return false, err }
+	f := bson.M{
+		"room_id": oid, "is_open": isOpen,
+		"start_at": bson.M{"$lt": end.UTC()},
+		"end_at":   bson.M{"$gt": start.UTC()},
+	}
+	if excludeID != "" {
+		wid, err := mustOID(excludeID); if err != nil { return false, err }
+		f["_id"] = bson.M{"$ne": wid}
+	}
+	cnt, err := r.d.Collection("room_schedules").CountDocuments(context.Background(), f)
 	return cnt > 0, err
 }
 
-func (r *roomRepoMongo) FindAvailable(minCapacity int, start, end string) ([]RoomRow, error) {
+func (r *roomRepoMongo) IsWithinOpenSchedule(roomID string, start, end time.Time) (bool, error) {
+	doc, err := r.getDoc(context.Background(), roomID)
+	if err != nil { return false, err }
+	return r.isOpen(context.Background(), doc, start, end)
+}
+
+// FindAvailable runs a fixed number of queries whatever the number of
+// rooms: the matching rooms, their open-time data (see loadOpenData), and
+// the bookings of the rooms still in the running.
+func (r *roomRepoMongo) FindAvailable(rf RoomFilter, start, end time.Time) ([]RoomRow, error) {
     ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
     defer cancel()
 
-    // 1) filter by capacity
-    cur, err := r.d.Collection("rooms").Find(ctx, bson.M{
-        "capacity": bson.M{"$gte": minCapacity},
-    }, options.Find().SetProjection(bson.M{"name": 1, "capacity": 1}))
+    // 1) filter by capacity, amenities and location
+    q, err := rf.query()
+    if err != nil { return nil, err }
+    cur, err := r.d.Collection("rooms").Find(ctx, q, options.Find().SetProjection(bson.M{
+        "name": 1, "capacity": 1, "policy": 1, "time_zone": 1, "amenities": 1, "building_id": 1, "floor_id": 1,
+    }))
+    if err != nil { return nil, err }
+    var rooms []roomDoc
+    if err := cur.All(ctx, &rooms); err != nil { return nil, err }
+    if err := r.withZones(ctx, rooms); err != nil { return nil, err }
+
+    // 2) must be open (one-off windows or weekly hours) for all of [start, end)
+    od, err := r.loadOpenData(ctx, rooms, start, end)
+    if err != nil { return nil, err }
+    var open []roomDoc
+    var ids []primitive.ObjectID
+    var maxPad time.Duration
+    for _, rr := range rooms {
+        if !od.isOpen(rr, start, end) { continue } // closed => skip
+        open = append(open, rr)
+        ids = append(ids, rr.ID)
+        if ps, _ := rr.Policy.Pad(start, end); start.Sub(ps) > maxPad { maxPad = start.Sub(ps) }
+    }
+    if len(open) == 0 { return nil, nil }
+
+    // 3) must NOT have an overlapping confirmed booking, buffers included;
+    // fetch with the widest buffer, then apply each room's own
+    f := occupying()
+    f["room_id"] = bson.M{"$in": ids}
+    f["end_at"], f["start_at"] = bson.M{"$gt": start.Add(-maxPad).UTC()}, bson.M{"$lt": end.Add(maxPad).UTC()}
+    cur, err = r.d.Collection("bookings").Find(ctx, f, options.Find().SetProjection(bson.M{"room_id": 1, "start_at": 1, "end_at": 1}))
     if err != nil { return nil, err }
-    defer cur.Close(ctx)
+    var booked []struct {
+        RoomID primitive.ObjectID `bson:"room_id"`
+        Start  time.Time          `bson:"start_at"`
+        End    time.Time          `bson:"end_at"`
+    }
+    if err := cur.All(ctx, &booked); err != nil { return nil, err }
+    busy := map[primitive.ObjectID][]span{}
+    for _, b := range booked { busy[b.RoomID] = append(busy[b.RoomID], span{b.Start, b.End}) }
 
     var out []RoomRow
-    for cur.Next(ctx) {
-        var rr struct {
-            ID       primitive.ObjectID `bson:"_id"`
-            Name     string             `bson:"name"`
-            Capacity int                `bson:"capacity"`
+rooms:
+    for _, rr := range open {
+        ps, pe := rr.Policy.Pad(start, end)
+        for _, b := range busy[rr.ID] {
+            // overlap if end_at > start && start_at < end
+            if b.end.After(ps) && b.start.Before(pe) { continue rooms } // conflict => skip
         }
-        if err := cur.Decode(&rr); err != nil { return nil, err }
-
-		fmt.Println(start)
-
-        // 2) must have an OPEN schedule that fully covers [start, end)
-        openCnt, err := r.d.Collection("room_schedules").CountDocuments(ctx, bson.M{
-            "room_id":  rr.ID,
-            "is_open":  true,
-            "start_at": bson.M{"$lte": start},
-            "end_at":   bson.M{"$gte": end},
-        })
-        if err != nil { return nil, err }
-        if openCnt == 0 { continue } // no open window => skip
-
-		fmt.Println(openCnt)
-
-        // 3) must NOT have an overlapping confirmed booking
-        bookCnt, err := r.d.Collection("bookings").CountDocuments(ctx, bson.M{
-            "room_id": rr.ID,
-            "status":  "confirmed",
-            // overlap if NOT (end_at <= start || start_at >= end)
-            "$nor": []bson.M{
-                {"end_at":   bson.M{"$lte": start}},
-                {"start_at": bson.M{"$gte": end}},
-            },
-        })
-        if err != nil { return nil, err }
-        if bookCnt > 0 { continue } // conflict => skip
-
-        out = append(out, RoomRow{ID: rr.ID.Hex(), Name: rr.Name, Capacity: rr.Capacity})
+        out = append(out, rr.row())
     }
-    return out, cur.Err()
+    return out, nil
 }
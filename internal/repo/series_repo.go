@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SeriesRepo stores recurring booking series; the occurrences themselves are
+// ordinary bookings carrying the series_id.
+type SeriesRepo interface {
+	Create(s SeriesRow) (id string, err error)
+	GetByID(seriesID string) (SeriesRow, error)
+	SetStatus(seriesID string, status string) error
+	Truncate(seriesID string, until string) error
+}
+
+type SeriesRow struct {
+	ID       string    `json:"id"`
+	RoomID   string    `json:"room_id"`
+	UserID   string    `json:"user_id"`
+	Start    time.Time `json:"start"` // first occurrence
+	End      time.Time `json:"end"`
+	Freq     string    `json:"freq"` // "daily" | "weekly"
+	Interval int       `json:"interval"`
+	ByDay    []string  `json:"by_day,omitempty"` // "MO".."SU", weekly only
+	Until    string    `json:"until,omitempty"`
+	Count    int       `json:"count,omitempty"`
+	ExDates  []string  `json:"ex_dates,omitempty"` // YYYY-MM-DD
+	Status   string    `json:"status"`             // "active" | "cancelled"
+}
+
+type seriesRepoMongo struct{ d *mongo.Database }
+
+func NewSeriesRepoMongo(d *mongo.Database) SeriesRepo { return &seriesRepoMongo{d: d} }
+
+func (r *seriesRepoMongo) Create(s SeriesRow) (string, error) {
+	roid, err := mustOID(s.RoomID); if err != nil { return "", err }
+	uid,  err := mustOID(s.UserID); if err != nil { return "", err }
+	res, err := r.d.Collection("booking_series").InsertOne(context.Background(), bson.M{
+		"room_id": roid, "user_id": uid,
+		"start_at": s.Start.UTC(), "end_at": s.End.UTC(),
+		"freq": s.Freq, "interval": s.Interval, "by_day": s.ByDay,
+		"until": s.Until, "count": s.Count, "ex_dates": s.ExDates,
+		"status": s.Status, "created_at": time.Now().UTC(),
+	})
+	if err != nil { return "", err }
+	return oidHex(res.InsertedID.(primitive.ObjectID)), nil
+}
+
+func (r *seriesRepoMongo) GetByID(seriesID string) (SeriesRow, error) {
+	sid, err := mustOID(seriesID); if err != nil { return SeriesRow{}, err }
+	var doc struct {
+		ID       primitive.ObjectID `bson:"_id"`
+		RoomID   primitive.ObjectID `bson:"room_id"`
+		UserID   primitive.ObjectID `bson:"user_id"`
+		Start    time.Time          `bson:"start_at"`
+		End      time.Time          `bson:"end_at"`
+		Freq     string             `bson:"freq"`
+		Interval int                `bson:"interval"`
+		ByDay    []string           `bson:"by_day"`
+		Until    string             `bson:"until"`
+		Count    int                `bson:"count"`
+		ExDates  []string           `bson:"ex_dates"`
+		Status   string             `bson:"status"`
+	}
+	err = r.d.Collection("booking_series").FindOne(context.Background(), bson.M{"_id": sid}).Decode(&doc)
+	if err != nil { return SeriesRow{}, err }
+	return SeriesRow{
+		ID: oidHex(doc.ID), RoomID: oidHex(doc.RoomID), UserID: oidHex(doc.UserID),
+		Start: doc.Start, End: doc.End, Freq: doc.Freq, Interval: doc.Interval, ByDay: doc.ByDay,
+		Until: doc.Until, Count: doc.Count, ExDates: doc.ExDates, Status: doc.Status,
+	}, nil
+}
+
+func (r *seriesRepoMongo) SetStatus(seriesID string, status string) error {
+	sid, err := mustOID(seriesID); if err != nil { return err }
+	_, err = r.d.Collection("booking_series").UpdateOne(context.Background(),
+		bson.M{"_id": sid}, bson.M{"$set": bson.M{"status": status}})
+	return err
+}
+
+// Truncate ends the series' rule on the date until (YYYY-MM-DD, inclusive),
+// dropping any count.
+func (r *seriesRepoMongo) Truncate(seriesID string, until string) error {
+	sid, err := mustOID(seriesID); if err != nil { return err }
+	_, err = r.d.Collection("booking_series").UpdateOne(context.Background(),
+		bson.M{"_id": sid}, bson.M{"$set": bson.M{"until": until, "count": 0}})
+	return err
+}
@@ -11,6 +11,8 @@ type UserRepo interface {
 	Create(email string, passwordHash []byte) (id string, err error)
 	GetByEmail(email string) (id string, pwHash []byte, isAdmin bool, err error)
 	GetByID(id string) (email string, isAdmin bool, err error)
+	ListFavorites(userID string) (roomIDs []string, err error)
+	SetFavorite(userID, roomID string, favorite bool) error
 }
 
 type userRepoMongo struct{ d *mongo.Database }
@@ -48,4 +50,26 @@ func (r *userRepoMongo) GetByID(id string) (string, bool, error) {
 	err = r.d.Collection("users").FindOne(context.Background(), bson.M{"_id": oid}).Decode(&doc)
 	if err != nil { return "", false, err }
 	return doc.Email, doc.Admin, nil
-}
\ No newline at end of file
+}
+
+// ListFavorites returns the rooms the user has starred, oldest first.
+func (r *userRepoMongo) ListFavorites(userID string) ([]string, error) {
+	oid, err := mustOID(userID); if err != nil { return nil, err }
+	var doc struct {
+		Favorites []primitive.ObjectID `bson:"favorite_room_ids"`
+	}
+	err = r.d.Collection("users").FindOne(context.Background(), bson.M{"_id": oid}).Decode(&doc)
+	if err != nil { return nil, err }
+	out := make([]string, len(doc.Favorites))
+	for i, id := range doc.Favorites { out[i] = oidHex(id) }
+	return out, nil
+}
+
+func (r *userRepoMongo) SetFavorite(userID, roomID string, favorite bool) error {
+	oid, err := mustOID(userID); if err != nil { return err }
+	rid, err := mustOID(roomID); if err != nil { return err }
+	op := "$pull"
+	if favorite { op = "$addToSet" }
+	_, err = r.d.Collection("users").UpdateOne(context.Background(), bson.M{"_id": oid}, bson.M{op: bson.M{"favorite_room_ids": rid}})
+	return err
+}
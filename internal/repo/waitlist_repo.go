@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -11,42 +12,196 @@ import (
 )
 
 type WaitlistRepo interface {
-	Enqueue(roomID, userID string, start, end string) error
-	DequeueFirst(roomID string, start, end string) (userID string, ok bool, err error)
-	Delete(roomID, userID string, start, end string) error
+	Enqueue(roomID, userID string, start, end time.Time, partySize int) error
+	ListCovered(roomID string, start, end time.Time) ([]WaitlistRow, error)
+	ListByUser(userID string, endAfter time.Time) ([]WaitlistRow, error)
+	CountAhead(w WaitlistRow) (int, error)
+	Delete(roomID, userID string, start, end time.Time) error
+	EnqueueCriteria(userID string, start, end time.Time, f RoomFilter, partySize int) error
+	ListCriteriaCovered(start, end time.Time, room RoomRow) ([]WaitlistRow, error)
+	DeleteByID(entryID, userID string) error
+	Restore(w WaitlistRow) error
+	DeleteByRoom(roomID string) ([]WaitlistRow, error)
+}
+
+// WaitlistRow is either an entry for a specific room or, with RoomID empty,
+// one for any room matching the embedded filter.
+type WaitlistRow struct {
+	ID     string `json:"id"`
+	RoomID string `json:"room_id,omitempty"`
+	RoomFilter
+	PartySize int       `json:"party_size"`
+	UserID    string    `json:"user_id"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type waitlistDoc struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	RoomID      primitive.ObjectID `bson:"room_id,omitempty"`
+	MinCapacity int                `bson:"min_capacity,omitempty"`
+	Amenities   []string           `bson:"amenities,omitempty"`
+	BuildingID  primitive.ObjectID `bson:"building_id,omitempty"`
+	FloorID     primitive.ObjectID `bson:"floor_id,omitempty"`
+	PartySize   int                `bson:"party_size,omitempty"`
+	UserID      primitive.ObjectID `bson:"user_id"`
+	Start       time.Time          `bson:"start_at"`
+	End         time.Time          `bson:"end_at"`
+	CreatedAt   time.Time          `bson:"created_at"`
+}
+
+func (d waitlistDoc) row() WaitlistRow {
+	out := WaitlistRow{
+		ID: oidHex(d.ID), UserID: oidHex(d.UserID), PartySize: d.PartySize,
+		RoomFilter: RoomFilter{MinCapacity: d.MinCapacity, Amenities: d.Amenities},
+		Start: d.Start, End: d.End, CreatedAt: d.CreatedAt,
+	}
+	if !d.RoomID.IsZero() { out.RoomID = oidHex(d.RoomID) }
+	if !d.BuildingID.IsZero() { out.BuildingID = oidHex(d.BuildingID) }
+	if !d.FloorID.IsZero() { out.FloorID = oidHex(d.FloorID) }
+	return out
 }
 
 type waitlistRepoMongo struct{ d *mongo.Database }
 
 func NewWaitlistRepoMongo(d *mongo.Database) WaitlistRepo { return &waitlistRepoMongo{d: d} }
 
-func (r *waitlistRepoMongo) Enqueue(roomID, userID string, start, end string) error {
+func (r *waitlistRepoMongo) Enqueue(roomID, userID string, start, end time.Time, partySize int) error {
 	roid, err := mustOID(roomID); if err != nil { return err }
 	uid,  err := mustOID(userID); if err != nil { return err }
 	_, err = r.d.Collection("waitlist").InsertOne(context.Background(), bson.M{
-		"room_id": roid, "user_id": uid,
-		"start_at": start, "end_at": end, "created_at": time.Now().UTC(),
+		"room_id": roid, "user_id": uid, "party_size": partySize,
+		"start_at": start.UTC(), "end_at": end.UTC(), "created_at": time.Now().UTC(),
 	})
 	return err
 }
 
-func (r *waitlistRepoMongo) DequeueFirst(roomID string, start, end string) (string, bool, error) {
-	roid, err := mustOID(roomID); if err != nil { return "", false, err }
-	var doc struct{ UserID primitive.ObjectID `bson:"user_id"` }
-	err = r.d.Collection("waitlist").FindOneAndDelete(
-		context.Background(),
-		bson.M{"room_id": roid, "start_at": start, "end_at": end},
-		options.FindOneAndDelete().SetSort(bson.D{{Key: "created_at", Value: 1}}),
-	).Decode(&doc)
-	if err == mongo.ErrNoDocuments { return "", false, nil }
-	if err != nil { return "", false, err }
-	return oidHex(doc.UserID), true, nil
+// ListCovered returns the entries for roomID whose wanted interval lies
+// within [start, end), first come first served.
+func (r *waitlistRepoMongo) ListCovered(roomID string, start, end time.Time) ([]WaitlistRow, error) {
+	roid, err := mustOID(roomID); if err != nil { return nil, err }
+	return r.find(bson.M{"room_id": roid, "start_at": bson.M{"$gte": start.UTC()}, "end_at": bson.M{"$lte": end.UTC()}},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+}
+
+// ListByUser returns the user's entries ending after endAfter, ordered by start.
+func (r *waitlistRepoMongo) ListByUser(userID string, endAfter time.Time) ([]WaitlistRow, error) {
+	uid, err := mustOID(userID); if err != nil { return nil, err }
+	return r.find(bson.M{"user_id": uid, "end_at": bson.M{"$gt": endAfter.UTC()}},
+		options.Find().SetSort(bson.D{{Key: "start_at", Value: 1}}))
+}
+
+// CountAhead counts the older entries of the same kind (same room, or any
+// room) whose interval overlaps w's, i.e. the ones that would be served
+// before it.
+func (r *waitlistRepoMongo) CountAhead(w WaitlistRow) (int, error) {
+	f := bson.M{
+		"room_id": nil, "created_at": bson.M{"$lt": w.CreatedAt},
+		"start_at": bson.M{"$lt": w.End.UTC()}, "end_at": bson.M{"$gt": w.Start.UTC()},
+	}
+	if w.RoomID != "" {
+		roid, err := mustOID(w.RoomID); if err != nil { return 0, err }
+		f["room_id"] = roid
+	}
+	n, err := r.d.Collection("waitlist").CountDocuments(context.Background(), f)
+	return int(n), err
+}
+
+// EnqueueCriteria adds an entry for any room matching f.
+func (r *waitlistRepoMongo) EnqueueCriteria(userID string, start, end time.Time, f RoomFilter, partySize int) error {
+	uid, err := mustOID(userID); if err != nil { return err }
+	doc := bson.M{
+		"user_id": uid, "min_capacity": f.MinCapacity, "party_size": partySize,
+		"start_at": start.UTC(), "end_at": end.UTC(), "created_at": time.Now().UTC(),
+	}
+	if len(f.Amenities) > 0 { doc["amenities"] = f.Amenities }
+	for field, hex := range map[string]string{"building_id": f.BuildingID, "floor_id": f.FloorID} {
+		if hex == "" { continue }
+		id, err := mustOID(hex); if err != nil { return err }
+		doc[field] = id
+	}
+	_, err = r.d.Collection("waitlist").InsertOne(context.Background(), doc)
+	return err
+}
+
+// ListCriteriaCovered returns the any-room entries whose interval lies within
+// [start, end) and whose filter room satisfies the way FindAvailable would
+// apply it, first come first served.
+func (r *waitlistRepoMongo) ListCriteriaCovered(start, end time.Time, room RoomRow) ([]WaitlistRow, error) {
+	has := room.Amenities
+	if has == nil { has = []string{} }
+	f := bson.M{
+		"room_id": nil, "min_capacity": bson.M{"$lte": room.Capacity},
+		// every amenity the entry asks for is one the room has
+		"amenities": bson.M{"$not": bson.M{"$elemMatch": bson.M{"$nin": has}}},
+		"building_id": nil, "floor_id": nil,
+		"start_at": bson.M{"$gte": start.UTC()}, "end_at": bson.M{"$lte": end.UTC()},
+	}
+	for field, hex := range map[string]string{"building_id": room.BuildingID, "floor_id": room.FloorID} {
+		if hex == "" { continue }
+		id, err := mustOID(hex); if err != nil { return nil, err }
+		f[field] = bson.M{"$in": bson.A{nil, id}}
+	}
+	return r.find(f, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+}
+
+func (r *waitlistRepoMongo) DeleteByID(entryID, userID string) error {
+	id,  err := mustOID(entryID); if err != nil { return err }
+	uid, err := mustOID(userID);  if err != nil { return err }
+	res, err := r.d.Collection("waitlist").DeleteOne(context.Background(), bson.M{"_id": id, "user_id": uid})
+	if err != nil { return err }
+	if res.DeletedCount == 0 { return errors.New("waitlist entry not found") }
+	return nil
+}
+
+// Restore puts back an entry removed by DeleteByID with its ID and creation
+// time, so it keeps its place in the queue.
+func (r *waitlistRepoMongo) Restore(w WaitlistRow) error {
+	d := waitlistDoc{
+		MinCapacity: w.MinCapacity, Amenities: w.Amenities, PartySize: w.PartySize,
+		Start: w.Start.UTC(), End: w.End.UTC(), CreatedAt: w.CreatedAt.UTC(),
+	}
+	for _, f := range []struct {
+		hex string
+		id  *primitive.ObjectID
+	}{{w.ID, &d.ID}, {w.UserID, &d.UserID}, {w.RoomID, &d.RoomID}, {w.BuildingID, &d.BuildingID}, {w.FloorID, &d.FloorID}} {
+		if f.hex == "" { continue }
+		id, err := mustOID(f.hex); if err != nil { return err }
+		*f.id = id
+	}
+	_, err := r.d.Collection("waitlist").InsertOne(context.Background(), d)
+	return err
 }
 
-func (r *waitlistRepoMongo) Delete(roomID, userID string, start, end string) error {
+// DeleteByRoom removes every entry for roomID and returns them.
+func (r *waitlistRepoMongo) DeleteByRoom(roomID string) ([]WaitlistRow, error) {
+	roid, err := mustOID(roomID); if err != nil { return nil, err }
+	rows, err := r.find(bson.M{"room_id": roid}, nil)
+	if err != nil || len(rows) == 0 { return nil, err }
+	_, err = r.d.Collection("waitlist").DeleteMany(context.Background(), bson.M{"room_id": roid})
+	return rows, err
+}
+
+func (r *waitlistRepoMongo) find(filter bson.M, opts *options.FindOptions) ([]WaitlistRow, error) {
+	cur, err := r.d.Collection("waitlist").Find(context.Background(), filter, opts)
+	if err != nil { return nil, err }
+	defer cur.Close(context.Background())
+	var out []WaitlistRow
+	for cur.Next(context.Background()) {
+		var doc waitlistDoc
+		if err := cur.Decode(&doc); err != nil { return nil, err }
+		out = append(out, doc.row())
+	}
+	return out, cur.Err()
+}
+
+func (r *waitlistRepoMongo) Delete(roomID, userID string, start, end time.Time) error {
 	roid, err := mustOID(roomID); if err != nil { return err }
 	uid,  err := mustOID(userID); if err != nil { return err }
-	_, err = r.d.Collection("waitlist").DeleteOne(context.Background(),
-		bson.M{"room_id": roid, "user_id": uid, "start_at": start, "end_at": end})
-	return err
-}
\ No newline at end of file
+	res, err := r.d.Collection("waitlist").DeleteOne(context.Background(),
+		bson.M{"room_id": roid, "user_id": uid, "start_at": start.UTC(), "end_at": end.UTC()})
+	if err != nil { return err }
+	if res.DeletedCount == 0 { return errors.New("waitlist entry not found") }
+	return nil
+}
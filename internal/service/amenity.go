@@ -0,0 +1,39 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+var amenityKey = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+func (s *bookingService) CreateAmenity(key, name string) error {
+	if !amenityKey.MatchString(key) { return fmt.Errorf("invalid amenity key %q: use lowercase letters, digits, '-' and '_'", key) }
+	if name == "" { return errors.New("amenity name must not be empty") }
+	return s.amenities.Create(key, name)
+}
+
+// DeleteAmenity removes the amenity from the catalog and from all rooms.
+func (s *bookingService) DeleteAmenity(key string) error { return s.amenities.Delete(key) }
+
+// SetRoomAmenities replaces the room's amenities; every key must be in the
+// catalog.
+func (s *bookingService) SetRoomAmenities(roomID string, keys []string) error {
+	if _, err := s.rooms.GetByID(roomID); err != nil { return errors.New("room not found") }
+	catalog, err := s.amenities.List()
+	if err != nil { return err }
+	known := map[string]bool{}
+	for _, a := range catalog { known[a.Key] = true }
+	seen := map[string]bool{}
+	var out []string
+	for _, k := range keys {
+		if !known[k] { return fmt.Errorf("unknown amenity %q", k) }
+		if seen[k] { continue }
+		seen[k] = true
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return s.rooms.SetAmenities(roomID, out)
+}
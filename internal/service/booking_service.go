@@ -2,68 +2,653 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"studyroom/internal/repo"
 )
 
 type BookingService interface {
-	CreateRoom(name string, capacity int) (string, error)
+	CreateRoom(name string, capacity int, timeZone, buildingID, floorID string) (string, error)
 	ListRooms() ([]repo.RoomRow, error)
-	SetRoomSchedule(roomID string, start, end string, isOpen bool) error
-	CreateBooking(roomID, userID string, start, end string) (string, error)
+	UpdateRoom(roomID string, u repo.RoomUpdate, mask []string) (repo.RoomRow, error)
+	ArchiveRoom(roomID, mode, reason string) (*ScheduleResult, error)
+	RestoreRoom(roomID string) error
+	DeleteRoom(roomID string) error
+	CreateCampus(name string) (string, error)
+	CreateBuilding(b repo.BuildingRow) (string, error)
+	UpdateBuilding(b repo.BuildingRow) (repo.BuildingRow, error)
+	CreateFloor(buildingID string, level int, name string) (string, error)
+	CreateAmenity(key, name string) error
+	DeleteAmenity(key string) error
+	SetRoomAmenities(roomID string, keys []string) error
+	SetRoomSchedule(roomID string, start, end string, isOpen bool, conflictMode, reason string) (*ScheduleResult, error)
+	ListRoomSchedule(roomID string) ([]repo.ScheduleWindow, error)
+	GetRoomSchedule(roomID, windowID string) (repo.ScheduleWindow, error)
+	UpdateRoomSchedule(roomID, windowID string, start, end string) (*ScheduleResult, error)
+	DeleteRoomSchedule(roomID, windowID string) (*ScheduleResult, error)
+	SetRoomPolicy(roomID string, p repo.RoomPolicy) error
+	AddRoomHours(roomID string, h repo.OpeningHours) (string, error)
+	ListRoomHours(roomID string) ([]repo.OpeningHours, error)
+	DeleteRoomHours(roomID, hoursID string) error
+	PreviewHolidays(hs []repo.HolidayRow) ([]repo.BookingRow, error)
+	AddHolidays(hs []repo.HolidayRow, conflictMode string) ([]string, *ScheduleResult, error)
+	ListHolidays(from, to string) ([]repo.HolidayRow, error)
+	DeleteHoliday(holidayID string) error
+	CreateBooking(roomID, userID string, start, end string, d BookingDetails) (string, error)
+	ListBookings(userID string) ([]repo.BookingRow, error)
 	CancelBooking(bookingID, userID string) error
-	JoinWaitlist(roomID, userID string, start, end string) error
+	RescheduleBooking(bookingID, userID string, start, end string) error
+	JoinWaitlist(roomID, userID string, start, end string, partySize int) error
+	ListWaitlist(userID string) ([]WaitlistEntry, error)
+	LeaveWaitlist(roomID, userID string, start, end string) error
+	JoinAnyRoomWaitlist(userID string, start, end string, f repo.RoomFilter, partySize int) error
+	LeaveWaitlistEntry(entryID, userID string) error
+	ListOffers(userID string) ([]repo.BookingRow, error)
+	AcceptOffer(bookingID, userID string) error
+	DeclineOffer(bookingID, userID string) error
+	CreateRecurringBooking(roomID, userID string, start, end string, d BookingDetails, rule RecurrenceRule, mode string) (*SeriesResult, error)
+	GetSeries(seriesID, userID string) (repo.SeriesRow, []repo.BookingRow, error)
+	CancelSeries(seriesID, userID, fromBookingID string) (int, error)
+	CheckIn(bookingID, userID string) error
+	ReleaseNoShows() (int, error)
+	Quota(userID string) (*QuotaStatus, error)
+	RoomTimeline(viewerID string, admin bool, roomIDs []string, from, to string) ([]Timeline, error)
+	HoldSlot(roomID, userID string, start, end string, d BookingDetails) (bookingID string, expires time.Time, err error)
+	ConfirmHold(bookingID, userID string) error
+	ExpireHolds() (int, error)
+	ListNotifications(userID string) ([]repo.NotificationRow, error)
+	CreateGroupBooking(userID string, start, end string, spec GroupSpec, d BookingDetails) (*GroupResult, error)
+	GetGroup(groupID, userID string) (repo.GroupRow, []repo.BookingRow, error)
+	CancelGroup(groupID, userID string) (int, error)
+}
+
+// BookingConfig holds the tunable booking rules.
+type BookingConfig struct {
+	CheckInOpensBefore time.Duration // how long before start_at the owner may check in
+	CheckInClosesAfter time.Duration // how long after start_at check-in is still accepted
+	NoShowGrace        time.Duration // unchecked bookings are released this long after start_at
+	HoldTTL            time.Duration // how long a tentative hold blocks its slot
+	OfferTTL           time.Duration // how long a waitlisted user has to accept an offered slot
+
+	// per-user quotas; zero means unlimited
+	MaxActiveBookings int           // confirmed bookings that have not ended yet
+	MaxPerDay         time.Duration // booked time per UTC day
+	MaxPerWeek        time.Duration // booked time per UTC week (Monday to Sunday)
+	MaxSimultaneous   int           // bookings running at the same instant
+}
+
+func DefaultBookingConfig() BookingConfig {
+	return BookingConfig{
+		CheckInOpensBefore: 15 * time.Minute,
+		CheckInClosesAfter: 15 * time.Minute,
+		NoShowGrace:        15 * time.Minute,
+		HoldTTL:            10 * time.Minute,
+		OfferTTL:           30 * time.Minute,
+	}
+}
+
+// BookingDetails is the optional information a user gives when booking.
+type BookingDetails struct {
+	PartySize      int      // expected headcount including the owner; 0 means owner plus attendees
+	AttendeeEmails []string // other registered users taking part
+}
+
+// How a room hands freed slots to its waitlist (RoomPolicy.WaitlistMode).
+// Offer is the default.
+const (
+	WaitlistOffer    = "offer" // hold the slot until the user accepts or OfferTTL passes
+	WaitlistAutoBook = "auto"  // book it for the user straight away
+)
+
+// What SetRoomSchedule does with active bookings that a new closed window
+// overlaps. Reject is the default.
+const (
+	ClosureReject = "reject" // refuse the closure while they exist
+	ClosureCancel = "cancel" // cancel them and notify their owners
+)
+
+// ScheduleResult reports what a schedule change did to existing bookings.
+type ScheduleResult struct {
+	WindowID  string            `json:"window_id,omitempty"`
+	Conflicts []repo.BookingRow `json:"conflicts,omitempty"` // with ClosureReject: why a closure was refused
+	Cancelled []repo.BookingRow `json:"cancelled,omitempty"` // with ClosureCancel
+	Orphaned  []repo.BookingRow `json:"orphaned,omitempty"`  // still active, but no longer inside open time
+}
+
+// Creation modes for recurring bookings.
+const (
+	SeriesAllOrNothing = "all_or_nothing"
+	SeriesBestEffort   = "best_effort"
+)
+
+type SeriesResult struct {
+	SeriesID   string               `json:"series_id,omitempty"`
+	BookingIDs []string             `json:"booking_ids"`
+	Conflicts  []OccurrenceConflict `json:"conflicts"`
+}
+
+type OccurrenceConflict struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Reason string    `json:"reason"`
 }
 
 type bookingService struct {
-	rooms repo.RoomRepo
-	book  repo.BookingRepo
-	wait  repo.WaitlistRepo
+	rooms     repo.RoomRepo
+	book      repo.BookingRepo
+	wait      repo.WaitlistRepo
+	series    repo.SeriesRepo
+	groups    repo.GroupRepo
+	holidays  repo.HolidayRepo
+	amenities repo.AmenityRepo
+	locations repo.LocationRepo
+	users     repo.UserRepo
+	notes     repo.NotificationRepo
+	cfg       BookingConfig
 }
 
-func NewBookingService(r repo.RoomRepo, b repo.BookingRepo, w repo.WaitlistRepo) BookingService {
-	return &bookingService{rooms: r, book: b, wait: w}
+func NewBookingService(r repo.RoomRepo, b repo.BookingRepo, w repo.WaitlistRepo, sr repo.SeriesRepo, g repo.GroupRepo, h repo.HolidayRepo, a repo.AmenityRepo, l repo.LocationRepo, u repo.UserRepo, n repo.NotificationRepo, cfg BookingConfig) BookingService {
+	return &bookingService{rooms: r, book: b, wait: w, series: sr, groups: g, holidays: h, amenities: a, locations: l, users: u, notes: n, cfg: cfg}
 }
 
-func (s *bookingService) CreateRoom(name string, capacity int) (string, error) {
+func (s *bookingService) CreateRoom(name string, capacity int, timeZone, buildingID, floorID string) (string, error) {
 	if name == "" || capacity <= 0 { return "", errors.New("invalid room") }
-	return s.rooms.Create(name, capacity)
+	if _, err := repo.LoadZone(timeZone); err != nil { return "", err }
+	if err := s.checkPlacement(buildingID, floorID); err != nil { return "", err }
+	return s.rooms.Create(name, capacity, timeZone, buildingID, floorID)
 }
 func (s *bookingService) ListRooms() ([]repo.RoomRow, error) { return s.rooms.List() }
 
-func (s *bookingService) SetRoomSchedule(roomID string, start, end string, isOpen bool) error {
-	if _, err := time.Parse(time.RFC3339, start); err != nil { return err }
-	if _, err := time.Parse(time.RFC3339, end); err != nil { return err }
-	if end <= start { return errors.New("end must be after start") }
-	return s.rooms.SetSchedule(roomID, start, end, isOpen)
+func (s *bookingService) SetRoomSchedule(roomID string, start, end string, isOpen bool, conflictMode, reason string) (*ScheduleResult, error) {
+	_, st, en, err := s.roomRange(roomID, start, end)
+	if err != nil { return nil, err }
+	if err := s.checkWindowOverlap(roomID, st, en, isOpen, ""); err != nil { return nil, err }
+	if isOpen {
+		id, err := s.rooms.SetSchedule(roomID, st, en, true)
+		if err != nil { return nil, err }
+		// a newly opened window may serve waiting users
+		s.promoteWaitlist(roomID, st, en)
+		return &ScheduleResult{WindowID: id}, nil
+	}
+
+	switch conflictMode {
+	case "", ClosureReject:
+		if res, err := s.closureConflicts(roomID, st, en); err != nil { return res, err }
+		id, err := s.rooms.SetSchedule(roomID, st, en, false)
+		if err != nil { return nil, err }
+		return &ScheduleResult{WindowID: id}, nil
+	case ClosureCancel:
+		// close first so nothing new can be booked into the window meanwhile
+		id, err := s.rooms.SetSchedule(roomID, st, en, false)
+		if err != nil { return nil, err }
+		conflicts, err := s.book.ListOccupying(roomID, st, en)
+		if err != nil { return nil, err }
+		if reason == "" { reason = "the room is closed" }
+		res := &ScheduleResult{WindowID: id}
+		for _, b := range s.localize(conflicts) {
+			ok, err := s.cancelForClosure(&b, reason)
+			if err != nil { return res, err }
+			if ok { res.Cancelled = append(res.Cancelled, b) }
+		}
+		return res, nil
+	default:
+		return nil, fmt.Errorf("unknown conflict mode %q", conflictMode)
+	}
+}
+
+// cancelForClosure cancels b on its owner's behalf and tells them why;
+// false means it was no longer active.
+func (s *bookingService) cancelForClosure(b *repo.BookingRow, reason string) (bool, error) {
+	ok, err := s.book.CancelWithReason(b.ID, reason)
+	if err != nil || !ok { return false, err }
+	b.Status, b.CancelReason = "cancelled", reason
+	s.notify(b.UserID, "booking_cancelled", fmt.Sprintf("Your booking of room %s, %s to %s was cancelled: %s",
+		b.RoomID, b.Start.Format(time.RFC3339), b.End.Format(time.RFC3339), reason))
+	return true, nil
 }
 
-func (s *bookingService) CreateBooking(roomID, userID string, start, end string) (string, error) {
-	if end <= start { return "", errors.New("invalid time range") }
-	ok, err := s.rooms.IsWithinOpenSchedule(roomID, start, end)
+func (s *bookingService) SetRoomPolicy(roomID string, p repo.RoomPolicy) error {
+	if err := validatePolicy(p); err != nil { return err }
+	return s.rooms.SetPolicy(roomID, p)
+}
+
+func (s *bookingService) CreateBooking(roomID, userID string, start, end string, d BookingDetails) (string, error) {
+	room, st, en, err := s.roomRange(roomID, start, end)
 	if err != nil { return "", err }
-	if !ok { return "", errors.New("room not open in this interval") }
-	over, err := s.book.HasOverlap(roomID, start, end)
+	party, attendees, err := s.resolveDetails(userID, d)
 	if err != nil { return "", err }
-	if over { return "", errors.New("room already booked in this interval") }
-	return s.book.Create(roomID, userID, start, end)
+	if err := s.checkSlot(room, st, en, party, ""); err != nil {
+		if errors.Is(err, ErrRoomClosed) || errors.Is(err, ErrRoomBooked) { return "", s.unavailable(err, room, st, en, party) }
+		return "", err
+	}
+	if err := s.checkQuota(userID, st, en, "", nil); err != nil { return "", err }
+	return s.book.Create(roomID, userID, st, en, party, attendees)
+}
+
+// ListBookings returns the upcoming bookings the user owns or attends.
+func (s *bookingService) ListBookings(userID string) ([]repo.BookingRow, error) {
+	rows, err := s.book.ListVisibleTo(userID, time.Now())
+	if err != nil { return nil, err }
+	return s.localize(rows), nil
+}
+
+// resolveDetails turns attendee emails into user IDs and fills in the party size.
+func (s *bookingService) resolveDetails(userID string, d BookingDetails) (int, []string, error) {
+	seen := map[string]bool{userID: true}
+	var attendees []string
+	for _, email := range d.AttendeeEmails {
+		email = strings.TrimSpace(strings.ToLower(email))
+		id, _, _, err := s.users.GetByEmail(email)
+		if err != nil { return 0, nil, fmt.Errorf("attendee %s is not a registered user", email) }
+		if seen[id] { continue }
+		seen[id] = true
+		attendees = append(attendees, id)
+	}
+	party := d.PartySize
+	if party == 0 { party = len(attendees) + 1 }
+	if party < len(attendees)+1 { return 0, nil, errors.New("party size is smaller than the attendee list") }
+	return party, attendees, nil
+}
+
+// checkSlot reports why [start, end) cannot be booked in room for a party
+// of partySize, or nil if it can. The booking excludeID, if set, does not
+// count as a clash, so a booking can be checked for a move.
+func (s *bookingService) checkSlot(room repo.RoomRow, start, end time.Time, partySize int, excludeID string) error {
+	p := room.Policy
+	if room.Archived { return errors.New("room is archived") }
+	if err := checkPartySize(room, partySize); err != nil { return err }
+	if err := checkPolicy(p, start.In(room.Location()), end.In(room.Location()), time.Now()); err != nil { return err }
+	ok, err := s.rooms.IsWithinOpenSchedule(room.ID, start, end)
+	if err != nil { return err }
+	if !ok { return ErrRoomClosed }
+	ps, pe := p.Pad(start, end)
+	var over bool
+	if excludeID == "" {
+		over, err = s.book.HasOverlap(room.ID, ps, pe)
+	} else {
+		over, err = s.book.HasOverlapExcluding(room.ID, ps, pe, excludeID)
+	}
+	if err != nil { return err }
+	if over { return ErrRoomBooked }
+	return nil
 }
 
 func (s *bookingService) CancelBooking(bookingID, userID string) error {
 	roomID, _, start, end, status, err := s.book.GetByID(bookingID)
 	if err != nil { return err }
-	if status != "confirmed" { return nil }
+	if status != "confirmed" && status != "held" { return nil }
 	if err := s.book.Cancel(bookingID, userID); err != nil { return err }
-	if uid, ok, err := s.wait.DequeueFirst(roomID, start, end); err == nil && ok {
-		if over, _ := s.book.HasOverlap(roomID, start, end); !over {
-			_, _ = s.book.Create(roomID, uid, start, end)
-		}
+	s.promoteWaitlist(roomID, start, end)
+	return nil
+}
+
+func (s *bookingService) RescheduleBooking(bookingID, userID string, start, end string) error {
+	b, err := s.book.GetRow(bookingID)
+	if err != nil { return err }
+	if b.UserID != userID { return errors.New("booking not found") }
+	if b.Status != "confirmed" { return errors.New("booking is not active") }
+	room, st, en, err := s.roomRange(b.RoomID, start, end)
+	if err != nil { return err }
+	party := b.PartySize
+	// bookings from before party sizes were stored
+	if party == 0 { party = 1 }
+	if err := s.checkSlot(room, st, en, party, bookingID); err != nil {
+		if errors.Is(err, ErrRoomClosed) || errors.Is(err, ErrRoomBooked) { return s.unavailable(err, room, st, en, party) }
+		return err
 	}
+	if err := s.checkQuota(userID, st, en, bookingID, nil); err != nil { return err }
+	if err := s.book.Reschedule(bookingID, userID, st, en); err != nil { return err }
+	// only hand out the old interval once the move has been committed
+	s.promoteWaitlist(b.RoomID, b.Start, b.End)
 	return nil
 }
 
-func (s *bookingService) JoinWaitlist(roomID, userID string, start, end string) error {
-	if end <= start { return errors.New("invalid time range") }
-	return s.wait.Enqueue(roomID, userID, start, end)
+// promoteWaitlist hands the freed [start, end) of roomID to the waitlist:
+// entries for this room and any-room entries the room satisfies are served
+// first come first served when their interval lies within the freed one and
+// still fits next to the bookings and offers made so far. Depending on the
+// room's waitlist mode the user gets an offer to accept or a booking. Every
+// entry gets the same checks as a fresh booking for its party. Entries that
+// cannot be served keep their place in the queue. Archived rooms serve
+// nobody, and neither does a slot shorter than the room's minimum duration.
+func (s *bookingService) promoteWaitlist(roomID string, start, end time.Time) {
+	room, err := s.rooms.GetByID(roomID)
+	if err != nil || room.Archived { return }
+	if end.Sub(start) < time.Duration(room.Policy.MinDurationMin)*time.Minute { return }
+	entries, err := s.wait.ListCovered(roomID, start, end)
+	if err != nil { return }
+	crit, err := s.wait.ListCriteriaCovered(start, end, room)
+	if err != nil { return }
+	entries = append(entries, crit...)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].CreatedAt.Before(entries[j].CreatedAt) })
+
+	p, loc := room.Policy, room.Location()
+	for _, e := range entries {
+		party := e.PartySize
+		// entries from before party sizes were stored
+		if party == 0 { party = 1 }
+		if party < e.MinCapacity { party = e.MinCapacity }
+		if s.checkSlot(room, e.Start, e.End, party, "") != nil { continue }
+		if s.checkQuota(e.UserID, e.Start, e.End, "", nil) != nil { continue }
+		// claim the entry first so a concurrent promotion cannot book it twice
+		if s.wait.DeleteByID(e.ID, e.UserID) != nil { continue }
+		if p.WaitlistMode == WaitlistAutoBook {
+			id, err := s.book.Create(roomID, e.UserID, e.Start, e.End, party, nil)
+			if err != nil { s.requeue(e, err); continue }
+			s.notify(e.UserID, "waitlist_booked", fmt.Sprintf("A slot you were waiting for was booked for you: room %s, %s to %s (booking %s)",
+				roomID, e.Start.In(loc).Format(time.RFC3339), e.End.In(loc).Format(time.RFC3339), id))
+			continue
+		}
+		exp := s.offerExpiry(e.Start)
+		id, err := s.book.CreateOffer(roomID, e.UserID, e.Start, e.End, party, exp)
+		if err != nil { s.requeue(e, err); continue }
+		s.notify(e.UserID, "waitlist_offer", fmt.Sprintf("A slot you were waiting for is free: room %s, %s to %s. Accept offer %s before %s",
+			roomID, e.Start.In(loc).Format(time.RFC3339), e.End.In(loc).Format(time.RFC3339), id, exp.In(loc).Format(time.RFC3339)))
+	}
+}
+
+// requeue puts back an entry whose booking or offer could not be created,
+// keeping its place in the queue. If that fails too the user is told to
+// join again.
+func (s *bookingService) requeue(e repo.WaitlistRow, cause error) {
+	log.Printf("promote waitlist entry %s: %v", e.ID, cause)
+	if err := s.wait.Restore(e); err != nil {
+		log.Printf("requeue waitlist entry %s: %v", e.ID, err)
+		s.notify(e.UserID, "waitlist_dropped", fmt.Sprintf("Your waitlist entry for %s to %s was lost while a freed slot was being offered; please join the waitlist again",
+			e.Start.Format(time.RFC3339), e.End.Format(time.RFC3339)))
+	}
+}
+
+// JoinWaitlist queues a party of partySize (0 means just the user) for
+// [start, end) in the room.
+func (s *bookingService) JoinWaitlist(roomID, userID string, start, end string, partySize int) error {
+	room, st, en, err := s.roomRange(roomID, start, end)
+	if err != nil { return err }
+	if room.Archived { return errors.New("room is archived") }
+	if partySize == 0 { partySize = 1 }
+	if err := checkPartySize(room, partySize); err != nil { return err }
+	if err := checkPolicy(room.Policy, st, en, time.Now()); err != nil { return err }
+	return s.wait.Enqueue(roomID, userID, st, en, partySize)
+}
+
+// JoinAnyRoomWaitlist queues a party of partySize for [start, end) in
+// whichever room matching f frees up or opens first, matched as
+// FindAvailable would. partySize defaults to f.MinCapacity, and the room
+// must seat the whole party.
+func (s *bookingService) JoinAnyRoomWaitlist(userID string, start, end string, f repo.RoomFilter, partySize int) error {
+	st, en, err := parseRange(start, end, time.UTC)
+	if err != nil { return err }
+	if partySize < 0 { return errors.New("party size must be at least 1") }
+	if f.MinCapacity < 1 { f.MinCapacity = 1 }
+	if partySize == 0 { partySize = f.MinCapacity }
+	if f.MinCapacity < partySize { f.MinCapacity = partySize }
+	if err := s.checkPlacement(f.BuildingID, f.FloorID); err != nil { return err }
+	return s.wait.EnqueueCriteria(userID, st, en, f, partySize)
+}
+
+// WaitlistEntry is one of the user's waitlist entries together with its
+// place in the queue; Position 1 is served first.
+type WaitlistEntry struct {
+	repo.WaitlistRow
+	Position int `json:"position"`
+}
+
+// ListWaitlist returns the user's waitlist entries that have not ended yet.
+func (s *bookingService) ListWaitlist(userID string) ([]WaitlistEntry, error) {
+	rows, err := s.wait.ListByUser(userID, time.Now())
+	if err != nil { return nil, err }
+	out := make([]WaitlistEntry, 0, len(rows))
+	for _, w := range rows {
+		ahead, err := s.wait.CountAhead(w)
+		if err != nil { return nil, err }
+		if w.RoomID != "" {
+			if room, err := s.rooms.GetByID(w.RoomID); err == nil {
+				w.Start, w.End = w.Start.In(room.Location()), w.End.In(room.Location())
+			}
+		}
+		out = append(out, WaitlistEntry{WaitlistRow: w, Position: ahead + 1})
+	}
+	return out, nil
+}
+
+func (s *bookingService) LeaveWaitlist(roomID, userID string, start, end string) error {
+	_, st, en, err := s.roomRange(roomID, start, end)
+	if err != nil { return err }
+	return s.wait.Delete(roomID, userID, st, en)
+}
+
+// LeaveWaitlistEntry removes one of the user's entries by its ID; this also
+// covers any-room entries, which have no room to name.
+func (s *bookingService) LeaveWaitlistEntry(entryID, userID string) error {
+	return s.wait.DeleteByID(entryID, userID)
+}
+
+// offerExpiry gives the user OfferTTL to accept, but no longer than until
+// the slot starts.
+func (s *bookingService) offerExpiry(start time.Time) time.Time {
+	now := time.Now().UTC()
+	exp := now.Add(s.cfg.OfferTTL)
+	if start.After(now) && start.Before(exp) { exp = start }
+	return exp
+}
+
+// ListOffers returns the waitlist offers the user can still accept.
+func (s *bookingService) ListOffers(userID string) ([]repo.BookingRow, error) {
+	rows, err := s.book.ListOffers(userID)
+	if err != nil { return nil, err }
+	return s.localize(rows), nil
+}
+
+// AcceptOffer turns an offer into a confirmed booking. Offers are holds, so
+// an unanswered one expires with the other holds and the slot moves on to
+// the next user in the queue.
+func (s *bookingService) AcceptOffer(bookingID, userID string) error {
+	return s.ConfirmHold(bookingID, userID)
+}
+
+// DeclineOffer gives the slot to the next user in the queue right away.
+func (s *bookingService) DeclineOffer(bookingID, userID string) error {
+	_, owner, _, _, status, err := s.book.GetByID(bookingID)
+	if err != nil { return err }
+	if owner != userID || status != "held" { return errors.New("offer not found") }
+	return s.CancelBooking(bookingID, userID)
+}
+
+func (s *bookingService) CreateRecurringBooking(roomID, userID string, start, end string, d BookingDetails, rule RecurrenceRule, mode string) (*SeriesResult, error) {
+	if mode == "" { mode = SeriesAllOrNothing }
+	if mode != SeriesAllOrNothing && mode != SeriesBestEffort { return nil, errors.New("invalid mode") }
+	room, st, en, err := s.roomRange(roomID, start, end)
+	if err != nil { return nil, err }
+	// expanding in the room's zone keeps the wall-clock time across DST changes
+	occs, err := expandRecurrence(st, en, rule)
+	if err != nil { return nil, err }
+	party, attendees, err := s.resolveDetails(userID, d)
+	if err != nil { return nil, err }
+
+	res := &SeriesResult{BookingIDs: []string{}, Conflicts: []OccurrenceConflict{}}
+	var free []occurrence
+	var pending []interval
+	for _, o := range occs {
+		err := s.checkSlot(room, o.Start, o.End, party, "")
+		if err == nil { err = s.checkQuota(userID, o.Start, o.End, "", pending) }
+		if err != nil {
+			res.Conflicts = append(res.Conflicts, OccurrenceConflict{Start: o.Start, End: o.End, Reason: err.Error()})
+			continue
+		}
+		free = append(free, o)
+		pending = append(pending, interval{o.Start, o.End})
+	}
+	if mode == SeriesAllOrNothing && len(res.Conflicts) > 0 {
+		return res, errors.New("recurring booking has conflicting occurrences")
+	}
+	if len(free) == 0 { return res, errors.New("no occurrence could be booked") }
+
+	sid, err := s.series.Create(repo.SeriesRow{
+		RoomID: roomID, UserID: userID, Start: st, End: en,
+		Freq: strings.ToLower(rule.Freq), Interval: rule.Interval, ByDay: rule.ByDay,
+		Until: rule.Until, Count: rule.Count, ExDates: rule.ExDates, Status: "active",
+	})
+	if err != nil { return nil, err }
+	for _, o := range free {
+		id, err := s.book.CreateInSeries(roomID, userID, sid, o.Start, o.End, party, attendees)
+		if err != nil {
+			if mode == SeriesAllOrNothing {
+				// undo what we already created so the series is all-or-nothing
+				for _, done := range res.BookingIDs { _ = s.book.Cancel(done, userID) }
+				_ = s.series.SetStatus(sid, "cancelled")
+				return nil, err
+			}
+			res.Conflicts = append(res.Conflicts, OccurrenceConflict{Start: o.Start, End: o.End, Reason: err.Error()})
+			continue
+		}
+		res.BookingIDs = append(res.BookingIDs, id)
+	}
+	res.SeriesID = sid
+	return res, nil
+}
+
+func (s *bookingService) GetSeries(seriesID, userID string) (repo.SeriesRow, []repo.BookingRow, error) {
+	ser, err := s.series.GetByID(seriesID)
+	if err != nil { return repo.SeriesRow{}, nil, err }
+	if ser.UserID != userID { return repo.SeriesRow{}, nil, errors.New("series not found") }
+	occs, err := s.book.ListBySeries(seriesID)
+	if err != nil { return repo.SeriesRow{}, nil, err }
+	if room, err := s.rooms.GetByID(ser.RoomID); err == nil {
+		ser.Start, ser.End = ser.Start.In(room.Location()), ser.End.In(room.Location())
+	}
+	return ser, s.localize(occs), nil
+}
+
+// CancelSeries cancels the upcoming occurrences of a series. With fromBookingID
+// set only that occurrence and the ones after it are cancelled, and the
+// series' rule is cut short to end before them; otherwise the whole series
+// is. Returns the number of cancelled bookings.
+func (s *bookingService) CancelSeries(seriesID, userID, fromBookingID string) (int, error) {
+	ser, occs, err := s.GetSeries(seriesID, userID)
+	if err != nil { return 0, err }
+	var from time.Time
+	if fromBookingID != "" {
+		found := false
+		for _, b := range occs {
+			if b.ID == fromBookingID { found, from = true, b.Start }
+		}
+		if !found { return 0, errors.New("booking is not part of this series") }
+	}
+	now := time.Now()
+	n := 0
+	var cut time.Time // first cancelled occurrence
+	for _, b := range occs {
+		if b.Status != "confirmed" || b.Start.Before(from) || !b.Start.After(now) { continue }
+		if err := s.book.Cancel(b.ID, userID); err != nil { return n, err }
+		s.promoteWaitlist(b.RoomID, b.Start, b.End)
+		if n == 0 || b.Start.Before(cut) { cut = b.Start }
+		n++
+	}
+	if fromBookingID == "" || (n > 0 && !cut.After(ser.Start)) {
+		if err := s.series.SetStatus(seriesID, "cancelled"); err != nil { return n, err }
+		return n, nil
+	}
+	if n > 0 {
+		// occurrences share a wall-clock time, so ending the rule on the local
+		// day before the cut keeps every one before it
+		until := cut.In(ser.Start.Location()).AddDate(0, 0, -1).Format(repo.DateLayout)
+		if err := s.series.Truncate(seriesID, until); err != nil { return n, err }
+	}
+	return n, nil
+}
+
+func (s *bookingService) CheckIn(bookingID, userID string) error {
+	_, owner, start, _, status, err := s.book.GetByID(bookingID)
+	if err != nil { return err }
+	if owner != userID { return errors.New("booking not found") }
+	if status != "confirmed" { return errors.New("booking is not active") }
+	now := time.Now()
+	if now.Before(start.Add(-s.cfg.CheckInOpensBefore)) { return errors.New("check-in is not open yet") }
+	if now.After(start.Add(s.cfg.CheckInClosesAfter)) { return errors.New("check-in window has closed") }
+	return s.book.CheckIn(bookingID, userID)
+}
+
+// ReleaseNoShows marks running bookings nobody checked in to as no_show and
+// hands what is left of their slot to the waitlist. Returns the number of
+// released bookings.
+func (s *bookingService) ReleaseNoShows() (int, error) {
+	grace := s.cfg.NoShowGrace
+	// never release a booking whose owner may still check in
+	if grace < s.cfg.CheckInClosesAfter { grace = s.cfg.CheckInClosesAfter }
+	now := time.Now().UTC()
+	rows, err := s.book.ListUnchecked(now.Add(-grace), now)
+	if err != nil { return 0, err }
+	n := 0
+	for _, b := range rows {
+		ok, err := s.book.MarkNoShow(b.ID)
+		if err != nil { return n, err }
+		if !ok { continue }
+		// the booking has started, so only the rest of it can be offered
+		st := b.Start
+		if st.Before(now) { st = now }
+		s.promoteWaitlist(b.RoomID, st, b.End)
+		n++
+	}
+	return n, nil
+}
+
+// HoldSlot reserves [start, end) for HoldTTL while the user completes their
+// booking. The hold blocks the slot like a confirmed booking until it is
+// confirmed, cancelled or expires.
+func (s *bookingService) HoldSlot(roomID, userID string, start, end string, d BookingDetails) (string, time.Time, error) {
+	room, st, en, err := s.roomRange(roomID, start, end)
+	if err != nil { return "", time.Time{}, err }
+	party, attendees, err := s.resolveDetails(userID, d)
+	if err != nil { return "", time.Time{}, err }
+	if err := s.checkSlot(room, st, en, party, ""); err != nil { return "", time.Time{}, err }
+	if err := s.checkQuota(userID, st, en, "", nil); err != nil { return "", time.Time{}, err }
+	exp := time.Now().Add(s.cfg.HoldTTL).In(room.Location())
+	id, err := s.book.CreateHold(roomID, userID, st, en, party, attendees, exp)
+	if err != nil { return "", time.Time{}, err }
+	return id, exp, nil
+}
+
+func (s *bookingService) ConfirmHold(bookingID, userID string) error {
+	_, owner, start, end, status, err := s.book.GetByID(bookingID)
+	if err != nil { return err }
+	if owner != userID { return errors.New("hold not found") }
+	if status != "held" { return errors.New("booking is not a pending hold") }
+	// limits may have been lowered since the hold was made
+	if err := s.checkQuota(userID, start, end, bookingID, nil); err != nil { return err }
+	return s.book.ConfirmHold(bookingID, userID)
+}
+
+// ExpireHolds releases holds past their expiry and offers the freed slots to
+// the waitlist. Returns the number of released holds.
+func (s *bookingService) ExpireHolds() (int, error) {
+	rows, err := s.book.ListExpiredHolds(time.Now())
+	if err != nil { return 0, err }
+	n := 0
+	for _, b := range rows {
+		ok, err := s.book.ExpireHold(b.ID)
+		if err != nil { return n, err }
+		if !ok { continue }
+		if b.Offer {
+			s.notify(b.UserID, "waitlist_offer_expired", fmt.Sprintf("Your offer for room %s, %s to %s expired and was passed on",
+				b.RoomID, s.roomTime(b.RoomID, b.Start), s.roomTime(b.RoomID, b.End)))
+		}
+		s.promoteWaitlist(b.RoomID, b.Start, b.End)
+		n++
+	}
+	return n, nil
+}
+
+func (s *bookingService) ListNotifications(userID string) ([]repo.NotificationRow, error) {
+	return s.notes.ListByUser(userID, 50)
+}
+
+// notify is best effort: a failed notification never fails the booking change.
+func (s *bookingService) notify(userID, kind, message string) {
+	if err := s.notes.Create(userID, kind, message); err != nil {
+		log.Printf("notify %s: %v", userID, err)
+	}
 }
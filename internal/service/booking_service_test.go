@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"studyroom/internal/repo"
+)
+
+func TestReleaseNoShowsOffersTheRest(t *testing.T) {
+	tests := []struct {
+		name      string
+		minDur    int
+		wantOffer bool
+	}{
+		{"the rest of the slot is offered", 0, true},
+		{"a rest shorter than the minimum duration is not", 60, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now().UTC()
+			start, end := now.Add(-20*time.Minute), now.Add(40*time.Minute)
+			rooms := &fakeRooms{rooms: map[string]repo.RoomRow{"r1": {ID: "r1", Policy: repo.RoomPolicy{MinDurationMin: tt.minDur}}}}
+			book := &fakeBookings{rows: []repo.BookingRow{{ID: "b1", RoomID: "r1", UserID: "u1", Start: start, End: end, Status: "confirmed"}}}
+			wait := &fakeWaitlist{}
+			s := &bookingService{rooms: rooms, book: book, wait: wait, cfg: DefaultBookingConfig()}
+
+			n, err := s.ReleaseNoShows()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != 1 || book.rows[0].Status != "no_show" {
+				t.Fatalf("released %d, status %q; want 1 no_show", n, book.rows[0].Status)
+			}
+			if !tt.wantOffer {
+				if len(wait.asked) != 0 {
+					t.Errorf("offered %v, want nothing", wait.asked)
+				}
+				return
+			}
+			if len(wait.asked) != 1 {
+				t.Fatalf("offered %v, want one interval", wait.asked)
+			}
+			if iv := wait.asked[0]; iv.start.Before(now) || !iv.end.Equal(end) {
+				t.Errorf("offered %s to %s, want from now (%s) to %s", iv.start, iv.end, now, end)
+			}
+		})
+	}
+}
+
+func TestRescheduleBooking(t *testing.T) {
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day()+10, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+	rfc := func(h, m int) string { return at(h, m).Format(time.RFC3339) }
+	tests := []struct {
+		name       string
+		capacity   int
+		start, end string
+		want       string // error substring, "" for success
+		suggested  bool
+	}{
+		{"overlapping its own old slot", 4, rfc(10, 30), rfc(11, 30), "", false},
+		{"onto another booking", 4, rfc(12, 30), rfc(13, 30), "already booked", true},
+		{"room shrank below the party", 1, rfc(10, 30), rfc(11, 30), "exceeds room capacity", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			room := repo.RoomRow{ID: "r1", Name: "One", Capacity: tt.capacity}
+			rooms := &fakeRooms{
+				rooms: map[string]repo.RoomRow{"r1": room},
+				open:  map[string][]repo.Period{"r1": {{Start: day, End: day.AddDate(0, 0, 1)}}},
+			}
+			book := &fakeBookings{rows: []repo.BookingRow{
+				{ID: "b1", RoomID: "r1", UserID: "u1", Start: at(10, 0), End: at(11, 0), Status: "confirmed", PartySize: 2},
+				{ID: "b2", RoomID: "r1", UserID: "u2", Start: at(12, 0), End: at(13, 0), Status: "confirmed", PartySize: 1},
+			}}
+			s := &bookingService{rooms: rooms, book: book, wait: &fakeWaitlist{}}
+
+			err := s.RescheduleBooking("b1", "u1", tt.start, tt.end)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := book.rows[0].Start; !got.Equal(at(10, 30)) {
+					t.Errorf("booking starts at %s, want %s", got, at(10, 30))
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got %v, want an error mentioning %q", err, tt.want)
+			}
+			var ue *SlotUnavailableError
+			if got := errors.As(err, &ue); got != tt.suggested {
+				t.Errorf("suggestions attached = %v, want %v", got, tt.suggested)
+			}
+			if !book.rows[0].Start.Equal(at(10, 0)) {
+				t.Errorf("booking moved to %s despite the error", book.rows[0].Start)
+			}
+		})
+	}
+}
+
+func TestPromoteWaitlistKeepsPlaceOnFailedInsert(t *testing.T) {
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day()+10, 0, 0, 0, 0, time.UTC)
+	start, end := day.Add(10*time.Hour), day.Add(11*time.Hour)
+	tests := []struct {
+		name      string
+		mode      string
+		fails     int      // inserts that fail before they succeed again
+		wantQueue []string // entry IDs left, in order
+		wantOwner []string // owners of the bookings or offers made
+	}{
+		{"offer fails, the next in line gets it", WaitlistOffer, 1, []string{"w1"}, []string{"u2"}},
+		{"booking fails, the next in line gets it", WaitlistAutoBook, 1, []string{"w1"}, []string{"u2"}},
+		{"every insert fails", WaitlistOffer, 2, []string{"w1", "w2"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := &fakeRooms{rooms: map[string]repo.RoomRow{"r1": {ID: "r1", Capacity: 4, Policy: repo.RoomPolicy{WaitlistMode: tt.mode}}}}
+			fails := tt.fails
+			book := &fakeBookings{beforeCreate: func(string) error {
+				if fails == 0 {
+					return nil
+				}
+				fails--
+				return errors.New("insert failed")
+			}}
+			wait := &fakeWaitlist{rows: []repo.WaitlistRow{
+				{ID: "w1", RoomID: "r1", UserID: "u1", Start: start, End: end, CreatedAt: now.Add(-2 * time.Hour)},
+				{ID: "w2", RoomID: "r1", UserID: "u2", Start: start, End: end, CreatedAt: now.Add(-time.Hour)},
+			}}
+			s := &bookingService{rooms: rooms, book: book, wait: wait, notes: fakeNotes{}}
+
+			s.promoteWaitlist("r1", start, end)
+			var queue, owners []string
+			for _, w := range wait.rows {
+				queue = append(queue, w.ID)
+			}
+			for _, b := range book.rows {
+				owners = append(owners, b.UserID)
+			}
+			if !reflect.DeepEqual(queue, tt.wantQueue) {
+				t.Errorf("queue %v, want %v", queue, tt.wantQueue)
+			}
+			if !reflect.DeepEqual(owners, tt.wantOwner) {
+				t.Errorf("made for %v, want %v", owners, tt.wantOwner)
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"studyroom/internal/repo"
+)
+
+// The fakes embed the repo interfaces, so a test that reaches a method they
+// do not implement panics instead of passing silently.
+
+type fakeRooms struct {
+	repo.RoomRepo
+	rooms   map[string]repo.RoomRow
+	open    map[string][]repo.Period // by room, in order
+	queries int                      // calls to OpenTimes
+}
+
+func (f *fakeRooms) GetByID(id string) (repo.RoomRow, error) {
+	r, ok := f.rooms[id]
+	if !ok {
+		return repo.RoomRow{}, errors.New("not found")
+	}
+	return r, nil
+}
+
+func (f *fakeRooms) IsWithinOpenSchedule(string, time.Time, time.Time) (bool, error) {
+	return true, nil
+}
+
+// OpenTime clips the room's open periods to [start, end).
+func (f *fakeRooms) OpenTime(roomID string, start, end time.Time) ([]repo.Period, []repo.Period, error) {
+	var out []repo.Period
+	for _, p := range f.open[roomID] {
+		if p.Start.Before(start) {
+			p.Start = start
+		}
+		if p.End.After(end) {
+			p.End = end
+		}
+		if p.End.After(p.Start) {
+			out = append(out, p)
+		}
+	}
+	return out, nil, nil
+}
+
+// List returns every room, in no particular order.
+func (f *fakeRooms) List() ([]repo.RoomRow, error) {
+	var out []repo.RoomRow
+	for _, r := range f.rooms {
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// FindMatching returns every room, ordered by ID.
+func (f *fakeRooms) FindMatching(repo.RoomFilter) ([]repo.RoomRow, error) {
+	var out []repo.RoomRow
+	for _, r := range f.rooms {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// OpenTimes is OpenTime for each of roomIDs.
+func (f *fakeRooms) OpenTimes(roomIDs []string, start, end time.Time) ([]repo.RoomOpenTime, error) {
+	f.queries++
+	var out []repo.RoomOpenTime
+	for _, id := range roomIDs {
+		r, ok := f.rooms[id]
+		if !ok {
+			return nil, fmt.Errorf("room %s not found", id)
+		}
+		open, closed, _ := f.OpenTime(id, start, end)
+		out = append(out, repo.RoomOpenTime{Room: r, Open: open, Closed: closed})
+	}
+	return out, nil
+}
+
+// FindAvailable returns every room, in no particular order.
+func (f *fakeRooms) FindAvailable(repo.RoomFilter, time.Time, time.Time) ([]repo.RoomRow, error) {
+	var out []repo.RoomRow
+	for _, r := range f.rooms {
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+type fakeBookings struct {
+	repo.BookingRepo
+	rows []repo.BookingRow
+	// beforeCreate runs before each insert; it can slip in a competing
+	// booking or fail the insert.
+	beforeCreate func(roomID string) error
+	cancelErr    error
+	queries      int // calls to the ListOccupying methods
+}
+
+func (f *fakeBookings) insert(b repo.BookingRow) (string, error) {
+	if f.beforeCreate != nil {
+		if err := f.beforeCreate(b.RoomID); err != nil {
+			return "", err
+		}
+	}
+	b.ID = fmt.Sprintf("b%d", len(f.rows)+1)
+	f.rows = append(f.rows, b)
+	return b.ID, nil
+}
+
+// blocks reports whether b occupies any of [start, end) in roomID.
+func blocks(b repo.BookingRow, roomID string, start, end time.Time) bool {
+	return b.RoomID == roomID && (b.Status == "confirmed" || b.Status == "held") && b.End.After(start) && b.Start.Before(end)
+}
+
+func (f *fakeBookings) occupied(roomID string, start, end time.Time, excludeID string) bool {
+	for _, b := range f.rows {
+		if b.ID != excludeID && blocks(b, roomID, start, end) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeBookings) HasOverlap(roomID string, start, end time.Time) (bool, error) {
+	return f.occupied(roomID, start, end, ""), nil
+}
+
+func (f *fakeBookings) HasOverlapExcluding(roomID string, start, end time.Time, excludeID string) (bool, error) {
+	return f.occupied(roomID, start, end, excludeID), nil
+}
+
+func (f *fakeBookings) Create(roomID, userID string, start, end time.Time, partySize int, attendeeIDs []string) (string, error) {
+	return f.insert(repo.BookingRow{RoomID: roomID, UserID: userID, Start: start, End: end, Status: "confirmed", PartySize: partySize})
+}
+
+func (f *fakeBookings) CreateOffer(roomID, userID string, start, end time.Time, partySize int, expires time.Time) (string, error) {
+	return f.insert(repo.BookingRow{RoomID: roomID, UserID: userID, Start: start, End: end, Status: "held", PartySize: partySize, HoldExpiresAt: &expires, Offer: true})
+}
+
+func (f *fakeBookings) CreateInGroup(roomID, userID, groupID string, start, end time.Time, partySize int, attendeeIDs []string) (string, error) {
+	return f.insert(repo.BookingRow{RoomID: roomID, UserID: userID, GroupID: groupID, Start: start, End: end, Status: "confirmed", PartySize: partySize})
+}
+
+func (f *fakeBookings) ListOccupying(roomID string, start, end time.Time) ([]repo.BookingRow, error) {
+	return f.ListOccupyingRooms([]string{roomID}, start, end)
+}
+
+func (f *fakeBookings) ListOccupyingRooms(roomIDs []string, start, end time.Time) ([]repo.BookingRow, error) {
+	f.queries++
+	var out []repo.BookingRow
+	for _, b := range f.rows {
+		for _, id := range roomIDs {
+			if blocks(b, id, start, end) {
+				out = append(out, b)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out, nil
+}
+
+func (f *fakeBookings) GetRow(bookingID string) (repo.BookingRow, error) {
+	for _, b := range f.rows {
+		if b.ID == bookingID {
+			return b, nil
+		}
+	}
+	return repo.BookingRow{}, errors.New("not found")
+}
+
+func (f *fakeBookings) Reschedule(bookingID, userID string, start, end time.Time) error {
+	for i := range f.rows {
+		if f.rows[i].ID == bookingID && f.rows[i].UserID == userID {
+			f.rows[i].Start, f.rows[i].End = start, end
+			return nil
+		}
+	}
+	return errors.New("booking not found")
+}
+
+func (f *fakeBookings) ListBySeries(seriesID string) ([]repo.BookingRow, error) {
+	var out []repo.BookingRow
+	for _, b := range f.rows {
+		if b.SeriesID == seriesID {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeBookings) Cancel(bookingID, userID string) error {
+	if f.cancelErr != nil {
+		return f.cancelErr
+	}
+	for i := range f.rows {
+		if f.rows[i].ID == bookingID && f.rows[i].UserID == userID {
+			f.rows[i].Status = "cancelled"
+		}
+	}
+	return nil
+}
+
+func (f *fakeBookings) ListUnchecked(startBefore, endAfter time.Time) ([]repo.BookingRow, error) {
+	var out []repo.BookingRow
+	for _, b := range f.rows {
+		if b.Status == "confirmed" && b.Start.Before(startBefore) && b.End.After(endAfter) {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeBookings) MarkNoShow(bookingID string) (bool, error) {
+	for i := range f.rows {
+		if f.rows[i].ID == bookingID && f.rows[i].Status == "confirmed" {
+			f.rows[i].Status = "no_show"
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeBookings) ListByUser(userID string, endAfter time.Time) ([]repo.BookingRow, error) {
+	return nil, nil
+}
+
+type fakeWaitlist struct {
+	repo.WaitlistRepo
+	rows  []repo.WaitlistRow // room entries, in queue order
+	asked []interval         // each interval offered to a room's queue
+}
+
+func (f *fakeWaitlist) ListCovered(roomID string, start, end time.Time) ([]repo.WaitlistRow, error) {
+	f.asked = append(f.asked, interval{start, end})
+	var out []repo.WaitlistRow
+	for _, w := range f.rows {
+		if w.RoomID == roomID && !w.Start.Before(start) && !w.End.After(end) {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeWaitlist) ListCriteriaCovered(time.Time, time.Time, repo.RoomRow) ([]repo.WaitlistRow, error) {
+	return nil, nil
+}
+
+func (f *fakeWaitlist) DeleteByID(entryID, userID string) error {
+	for i, w := range f.rows {
+		if w.ID == entryID && w.UserID == userID {
+			f.rows = append(f.rows[:i], f.rows[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("waitlist entry not found")
+}
+
+func (f *fakeWaitlist) Restore(w repo.WaitlistRow) error {
+	f.rows = append(f.rows, w)
+	sort.SliceStable(f.rows, func(i, j int) bool { return f.rows[i].CreatedAt.Before(f.rows[j].CreatedAt) })
+	return nil
+}
+
+type fakeNotes struct{ repo.NotificationRepo }
+
+func (fakeNotes) Create(userID, kind, message string) error { return nil }
+
+type fakeSeries struct {
+	repo.SeriesRepo
+	row repo.SeriesRow
+}
+
+func (f *fakeSeries) GetByID(string) (repo.SeriesRow, error) { return f.row, nil }
+
+func (f *fakeSeries) SetStatus(_ string, status string) error {
+	f.row.Status = status
+	return nil
+}
+
+func (f *fakeSeries) Truncate(_ string, until string) error {
+	f.row.Until, f.row.Count = until, 0
+	return nil
+}
+
+type fakeGroups struct {
+	repo.GroupRepo
+	status map[string]string
+}
+
+func (f *fakeGroups) Create(g repo.GroupRow) (string, error) {
+	id := fmt.Sprintf("g%d", len(f.status)+1)
+	f.status[id] = g.Status
+	return id, nil
+}
+
+func (f *fakeGroups) SetStatus(groupID string, status string) error {
+	f.status[groupID] = status
+	return nil
+}
+
+type fakeLocations struct {
+	repo.LocationRepo
+	buildings []repo.BuildingRow
+}
+
+func (f *fakeLocations) GetBuilding(id string) (repo.BuildingRow, error) {
+	for _, b := range f.buildings {
+		if b.ID == id {
+			return b, nil
+		}
+	}
+	return repo.BuildingRow{}, errors.New("not found")
+}
+
+func (f *fakeLocations) ListBuildings(string) ([]repo.BuildingRow, error) { return f.buildings, nil }
+
+type fakeUsers struct {
+	repo.UserRepo
+	favorites []string
+}
+
+func (f *fakeUsers) ListFavorites(string) ([]string, error) { return f.favorites, nil }
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"studyroom/internal/repo"
+)
+
+// GroupSpec selects the rooms of a group booking: either the explicit
+// RoomIDs, or any Count rooms with at least MinCapacity seats and all of
+// Amenities, optionally all in BuildingID. The filters only apply to Count.
+type GroupSpec struct {
+	RoomIDs     []string
+	Count       int
+	MinCapacity int
+	Amenities   []string
+	BuildingID  string
+}
+
+type GroupResult struct {
+	GroupID    string         `json:"group_id,omitempty"`
+	BookingIDs []string       `json:"booking_ids"`
+	Conflicts  []RoomConflict `json:"conflicts"`
+}
+
+type RoomConflict struct {
+	RoomID string `json:"room_id"`
+	Reason string `json:"reason"`
+}
+
+// CreateGroupBooking books every room of spec for [start, end) or none of
+// them. d applies to each room; every member booking counts towards the
+// user's quotas on its own. A room taken while the group is being inserted
+// rolls the whole group back. Times without an offset are read in the zone of
+// the first listed room, or in UTC when rooms are picked by count.
+func (s *bookingService) CreateGroupBooking(userID string, start, end string, spec GroupSpec, d BookingDetails) (*GroupResult, error) {
+	loc := time.UTC
+	if len(spec.RoomIDs) > 0 {
+		if room, err := s.rooms.GetByID(spec.RoomIDs[0]); err == nil { loc = room.Location() }
+	}
+	st, en, err := parseRange(start, end, loc)
+	if err != nil { return nil, err }
+	party, attendees, err := s.resolveDetails(userID, d)
+	if err != nil { return nil, err }
+
+	res := &GroupResult{BookingIDs: []string{}, Conflicts: []RoomConflict{}}
+	var rooms []repo.RoomRow
+	var pending []interval
+	switch {
+	case len(spec.RoomIDs) > 0 && spec.Count > 0:
+		return nil, errors.New("give either room_ids or count, not both")
+	case len(spec.RoomIDs) > 0 && (spec.MinCapacity > 0 || len(spec.Amenities) > 0 || spec.BuildingID != ""):
+		return nil, errors.New("min_capacity, amenities and building_id only apply with count")
+	case len(spec.RoomIDs) > 0:
+		seen := map[string]bool{}
+		for _, id := range spec.RoomIDs {
+			if seen[id] { return nil, fmt.Errorf("room %s listed twice", id) }
+			seen[id] = true
+			room, err := s.rooms.GetByID(id)
+			if err != nil { err = errors.New("room not found") }
+			if err == nil { err = s.checkSlot(room, st, en, party, "") }
+			if err == nil { err = s.checkQuota(userID, st, en, "", pending) }
+			if err != nil {
+				res.Conflicts = append(res.Conflicts, RoomConflict{RoomID: id, Reason: err.Error()})
+				continue
+			}
+			rooms = append(rooms, room)
+			pending = append(pending, interval{st, en})
+		}
+		if len(res.Conflicts) > 0 { return res, errors.New("some rooms of the group cannot be booked") }
+	case spec.Count > 0:
+		minCap := spec.MinCapacity
+		if minCap < party { minCap = party }
+		cands, err := s.rooms.FindAvailable(repo.RoomFilter{MinCapacity: minCap, Amenities: spec.Amenities, BuildingID: spec.BuildingID}, st, en)
+		if err != nil { return nil, err }
+		// prefer the smallest rooms that fit so big ones stay free
+		sort.SliceStable(cands, func(i, j int) bool { return cands[i].Capacity < cands[j].Capacity })
+		for _, c := range cands {
+			if len(rooms) == spec.Count { break }
+			if s.checkSlot(c, st, en, party, "") != nil { continue }
+			if err := s.checkQuota(userID, st, en, "", pending); err != nil { return nil, err }
+			rooms = append(rooms, c)
+			pending = append(pending, interval{st, en})
+		}
+		if len(rooms) < spec.Count {
+			return nil, fmt.Errorf("only %d of %d rooms with capacity >= %d are free", len(rooms), spec.Count, minCap)
+		}
+	default:
+		return nil, errors.New("no rooms requested")
+	}
+
+	ids := make([]string, len(rooms))
+	for i, r := range rooms { ids[i] = r.ID }
+	gid, err := s.groups.Create(repo.GroupRow{UserID: userID, Start: st, End: en, RoomIDs: ids, Status: "active"})
+	if err != nil { return nil, err }
+	for _, room := range rooms {
+		id, err := s.book.CreateInGroup(room.ID, userID, gid, st, en, party, attendees)
+		if err != nil { return nil, errors.Join(err, s.rollbackGroup(gid, userID, res.BookingIDs)) }
+		res.BookingIDs = append(res.BookingIDs, id)
+		// the room was checked before the insert; a booking made since then
+		// would now overlap ours or its buffers
+		ps, pe := room.Policy.Pad(st, en)
+		clash, err := s.book.HasOverlapExcluding(room.ID, ps, pe, id)
+		if err != nil { return nil, errors.Join(err, s.rollbackGroup(gid, userID, res.BookingIDs)) }
+		if clash {
+			rbErr := s.rollbackGroup(gid, userID, res.BookingIDs)
+			res.BookingIDs = []string{}
+			res.Conflicts = append(res.Conflicts, RoomConflict{RoomID: room.ID, Reason: ErrRoomBooked.Error()})
+			return res, errors.Join(errors.New("some rooms of the group cannot be booked"), rbErr)
+		}
+	}
+	res.GroupID = gid
+	return res, nil
+}
+
+// rollbackGroup cancels the bookings already created for group gid and the
+// group itself so a failed group booking leaves nothing behind. The error
+// names every booking that could not be cancelled.
+func (s *bookingService) rollbackGroup(gid, userID string, bookingIDs []string) error {
+	var errs []error
+	for _, id := range bookingIDs {
+		if err := s.book.Cancel(id, userID); err != nil { errs = append(errs, fmt.Errorf("roll back booking %s: %w", id, err)) }
+	}
+	if err := s.groups.SetStatus(gid, "cancelled"); err != nil { errs = append(errs, fmt.Errorf("roll back group %s: %w", gid, err)) }
+	return errors.Join(errs...)
+}
+
+func (s *bookingService) GetGroup(groupID, userID string) (repo.GroupRow, []repo.BookingRow, error) {
+	g, err := s.groups.GetByID(groupID)
+	if err != nil { return repo.GroupRow{}, nil, err }
+	if g.UserID != userID { return repo.GroupRow{}, nil, errors.New("group not found") }
+	bs, err := s.book.ListByGroup(groupID)
+	if err != nil { return repo.GroupRow{}, nil, err }
+	return g, s.localize(bs), nil
+}
+
+// CancelGroup cancels every still-active booking of the group and releases
+// the rooms to their waitlists. Returns the number of cancelled bookings.
+func (s *bookingService) CancelGroup(groupID, userID string) (int, error) {
+	_, bs, err := s.GetGroup(groupID, userID)
+	if err != nil { return 0, err }
+	n := 0
+	for _, b := range bs {
+		if b.Status != "confirmed" { continue }
+		if err := s.book.Cancel(b.ID, userID); err != nil { return n, err }
+		s.promoteWaitlist(b.RoomID, b.Start, b.End)
+		n++
+	}
+	if err := s.groups.SetStatus(groupID, "cancelled"); err != nil { return n, err }
+	return n, nil
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"studyroom/internal/repo"
+)
+
+func TestCreateGroupBookingRollsBack(t *testing.T) {
+	start := time.Now().UTC().Add(24 * time.Hour).Truncate(time.Hour)
+	end := start.Add(time.Hour)
+	rooms := &fakeRooms{rooms: map[string]repo.RoomRow{}}
+	for _, id := range []string{"r1", "r2", "r3"} {
+		rooms.rooms[id] = repo.RoomRow{ID: id, Capacity: 4}
+	}
+	rooms.rooms["r4"] = repo.RoomRow{ID: "r4", Capacity: 4, Policy: repo.RoomPolicy{BufferAfterMin: 15}}
+	newService := func() (*bookingService, *fakeBookings, *fakeGroups) {
+		book := &fakeBookings{}
+		groups := &fakeGroups{status: map[string]string{}}
+		return &bookingService{rooms: rooms, book: book, wait: &fakeWaitlist{}, groups: groups}, book, groups
+	}
+	spec := GroupSpec{RoomIDs: []string{"r1", "r2", "r3"}}
+	st, en := start.Format(time.RFC3339), end.Format(time.RFC3339)
+
+	t.Run("all rooms booked", func(t *testing.T) {
+		s, book, _ := newService()
+		res, err := s.CreateGroupBooking("u1", st, en, spec, BookingDetails{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.BookingIDs) != 3 || len(book.rows) != 3 {
+			t.Errorf("got %d booking ids and %d rows, want 3", len(res.BookingIDs), len(book.rows))
+		}
+	})
+
+	t.Run("room taken after the check", func(t *testing.T) {
+		s, book, groups := newService()
+		// another user books r2 between the group's checks and its insert
+		book.beforeCreate = func(roomID string) error {
+			if roomID == "r2" {
+				book.rows = append(book.rows, repo.BookingRow{ID: "other", RoomID: "r2", UserID: "u2", Start: start, End: end, Status: "confirmed"})
+			}
+			return nil
+		}
+		res, err := s.CreateGroupBooking("u1", st, en, spec, BookingDetails{})
+		if err == nil {
+			t.Fatal("double-booked r2 instead of rolling back")
+		}
+		if res == nil || len(res.Conflicts) != 1 || res.Conflicts[0].RoomID != "r2" || len(res.BookingIDs) != 0 {
+			t.Errorf("result = %+v, want one conflict on r2 and no bookings", res)
+		}
+		for _, b := range book.rows {
+			if b.UserID == "u1" && b.Status != "cancelled" {
+				t.Errorf("booking %s in %s left %s", b.ID, b.RoomID, b.Status)
+			}
+			if b.ID == "other" && b.Status != "confirmed" {
+				t.Errorf("the competing booking was %s", b.Status)
+			}
+		}
+		if groups.status["g1"] != "cancelled" {
+			t.Errorf("group left %s", groups.status["g1"])
+		}
+	})
+
+	t.Run("room taken within its buffer after the check", func(t *testing.T) {
+		s, book, _ := newService()
+		// the competing booking starts inside r4's cleanup buffer after ours
+		book.beforeCreate = func(roomID string) error {
+			if roomID == "r4" {
+				book.rows = append(book.rows, repo.BookingRow{ID: "other", RoomID: "r4", UserID: "u2", Start: end.Add(5 * time.Minute), End: end.Add(time.Hour), Status: "confirmed"})
+			}
+			return nil
+		}
+		res, err := s.CreateGroupBooking("u1", st, en, GroupSpec{RoomIDs: []string{"r1", "r4"}}, BookingDetails{})
+		if err == nil {
+			t.Fatal("booked r4 into the competing booking's buffer instead of rolling back")
+		}
+		if res == nil || len(res.Conflicts) != 1 || res.Conflicts[0].RoomID != "r4" {
+			t.Errorf("result = %+v, want one conflict on r4", res)
+		}
+	})
+
+	t.Run("insert fails", func(t *testing.T) {
+		s, book, groups := newService()
+		book.beforeCreate = func(roomID string) error {
+			if roomID == "r3" {
+				return errors.New("insert failed")
+			}
+			return nil
+		}
+		if _, err := s.CreateGroupBooking("u1", st, en, spec, BookingDetails{}); err == nil {
+			t.Fatal("want the insert error")
+		}
+		for _, b := range book.rows {
+			if b.Status != "cancelled" {
+				t.Errorf("booking %s in %s left %s", b.ID, b.RoomID, b.Status)
+			}
+		}
+		if groups.status["g1"] != "cancelled" {
+			t.Errorf("group left %s", groups.status["g1"])
+		}
+	})
+
+	t.Run("rollback failure is reported", func(t *testing.T) {
+		s, book, _ := newService()
+		book.beforeCreate = func(roomID string) error {
+			if roomID == "r3" {
+				book.cancelErr = errors.New("connection lost")
+				return errors.New("insert failed")
+			}
+			return nil
+		}
+		_, err := s.CreateGroupBooking("u1", st, en, spec, BookingDetails{})
+		if err == nil {
+			t.Fatal("want an error")
+		}
+		for _, want := range []string{"insert failed", "roll back booking b1", "roll back booking b2", "connection lost"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error %q does not mention %q", err, want)
+			}
+		}
+	})
+}
+
+func TestCreateGroupBookingSpecErrors(t *testing.T) {
+	s := &bookingService{rooms: &fakeRooms{}, book: &fakeBookings{}}
+	start := time.Now().UTC().Add(24 * time.Hour).Truncate(time.Hour)
+	st, en := start.Format(time.RFC3339), start.Add(time.Hour).Format(time.RFC3339)
+	tests := []struct {
+		name, want string
+		spec       GroupSpec
+	}{
+		{"rooms and count", "not both", GroupSpec{RoomIDs: []string{"r1"}, Count: 2}},
+		{"rooms and min capacity", "only apply with count", GroupSpec{RoomIDs: []string{"r1"}, MinCapacity: 6}},
+		{"rooms and amenities", "only apply with count", GroupSpec{RoomIDs: []string{"r1"}, Amenities: []string{"projector"}}},
+		{"rooms and building", "only apply with count", GroupSpec{RoomIDs: []string{"r1"}, BuildingID: "b1"}},
+		{"nothing", "no rooms", GroupSpec{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.CreateGroupBooking("u1", st, en, tt.spec, BookingDetails{}); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
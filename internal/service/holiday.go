@@ -0,0 +1,122 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"studyroom/internal/repo"
+)
+
+// PreviewHolidays lists the active bookings that saving hs would run into,
+// without saving anything.
+func (s *bookingService) PreviewHolidays(hs []repo.HolidayRow) ([]repo.BookingRow, error) {
+	if err := s.validateHolidays(hs); err != nil { return nil, err }
+	out, _, err := s.holidayConflicts(hs)
+	return out, err
+}
+
+// AddHolidays saves hs to the closure calendar. Bookings on those dates are
+// handled like those under a closed schedule window (see ClosureReject and
+// ClosureCancel).
+func (s *bookingService) AddHolidays(hs []repo.HolidayRow, conflictMode string) ([]string, *ScheduleResult, error) {
+	if err := s.validateHolidays(hs); err != nil { return nil, nil, err }
+	switch conflictMode {
+	case "", ClosureReject:
+		conflicts, _, err := s.holidayConflicts(hs)
+		if err != nil { return nil, nil, err }
+		if len(conflicts) > 0 {
+			return nil, &ScheduleResult{Conflicts: conflicts}, fmt.Errorf("holidays overlap %d active bookings", len(conflicts))
+		}
+	case ClosureCancel:
+	default:
+		return nil, nil, fmt.Errorf("unknown conflict mode %q", conflictMode)
+	}
+
+	var ids []string
+	for _, h := range hs {
+		id, err := s.holidays.Add(h)
+		if err != nil { return ids, nil, err }
+		ids = append(ids, id)
+	}
+	if conflictMode != ClosureCancel { return ids, nil, nil }
+
+	// the dates are closed now, so the list cannot grow while we work on it
+	conflicts, names, err := s.holidayConflicts(hs)
+	if err != nil { return ids, nil, err }
+	res := &ScheduleResult{}
+	for _, b := range conflicts {
+		ok, err := s.cancelForClosure(&b, "the room is closed for "+names[b.ID])
+		if err != nil { return ids, res, err }
+		if ok { res.Cancelled = append(res.Cancelled, b) }
+	}
+	return ids, res, nil
+}
+
+func (s *bookingService) ListHolidays(from, to string) ([]repo.HolidayRow, error) {
+	return s.holidays.List(from, to)
+}
+
+func (s *bookingService) DeleteHoliday(holidayID string) error {
+	return s.holidays.Delete(holidayID)
+}
+
+func (s *bookingService) validateHolidays(hs []repo.HolidayRow) error {
+	if len(hs) == 0 { return errors.New("no holidays given") }
+	known := map[string]bool{}
+	for _, h := range hs {
+		if _, err := time.Parse(repo.DateLayout, h.Date); err != nil { return fmt.Errorf("invalid date %q: want YYYY-MM-DD", h.Date) }
+		if h.Name == "" { return fmt.Errorf("holiday on %s needs a name", h.Date) }
+		if h.BuildingID == "" || known[h.BuildingID] { continue }
+		if _, err := s.locations.GetBuilding(h.BuildingID); err != nil { return fmt.Errorf("building %s not found", h.BuildingID) }
+		known[h.BuildingID] = true
+	}
+	return nil
+}
+
+// holidayConflicts finds the active bookings in any room that touch one of
+// the holidays' dates in that room's time zone; a building's holidays only
+// reach its own rooms. names maps each booking to the holiday it falls on.
+// Rooms sharing a time zone share the date's interval, so each holiday
+// costs one query per zone.
+func (s *bookingService) holidayConflicts(hs []repo.HolidayRow) ([]repo.BookingRow, map[string]string, error) {
+	rooms, err := s.rooms.List()
+	if err != nil { return nil, nil, err }
+	var out []repo.BookingRow
+	names := map[string]string{}
+	for _, h := range hs {
+		var zones []zoneRooms
+		at := map[string]int{}
+		for _, room := range rooms {
+			if h.BuildingID != "" && h.BuildingID != room.BuildingID { continue }
+			loc := room.Location()
+			i, ok := at[loc.String()]
+			if !ok {
+				i, at[loc.String()] = len(zones), len(zones)
+				zones = append(zones, zoneRooms{loc: loc})
+			}
+			zones[i].ids = append(zones[i].ids, room.ID)
+		}
+		for _, z := range zones {
+			loc := z.loc
+			day, _ := time.ParseInLocation(repo.DateLayout, h.Date, loc)
+			bs, err := s.book.ListOccupyingRooms(z.ids, day, day.AddDate(0, 0, 1))
+			if err != nil { return nil, nil, err }
+			for _, b := range bs {
+				if _, dup := names[b.ID]; dup { continue }
+				names[b.ID] = h.Name
+				b.Start, b.End = b.Start.In(loc), b.End.In(loc)
+				out = append(out, b)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out, names, nil
+}
+
+// zoneRooms are the IDs of rooms in one time zone.
+type zoneRooms struct {
+	loc *time.Location
+	ids []string
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"studyroom/internal/repo"
+)
+
+func TestHolidayConflicts(t *testing.T) {
+	berlin, newYork := mustZone(t, "Europe/Berlin"), mustZone(t, "America/New_York")
+	rooms := &fakeRooms{rooms: map[string]repo.RoomRow{
+		"r1": {ID: "r1", BuildingID: "b1", TimeZone: "Europe/Berlin"},
+		"r2": {ID: "r2", BuildingID: "b1", TimeZone: "Europe/Berlin"},
+		"r3": {ID: "r3", BuildingID: "b2", TimeZone: "America/New_York"},
+	}}
+	booking := func(id, roomID string, start time.Time) repo.BookingRow {
+		return repo.BookingRow{ID: id, RoomID: roomID, Start: start, End: start.Add(time.Hour), Status: "confirmed"}
+	}
+	book := &fakeBookings{rows: []repo.BookingRow{
+		booking("x1", "r1", time.Date(2030, 12, 25, 10, 0, 0, 0, berlin)),
+		booking("x2", "r2", time.Date(2030, 12, 24, 23, 0, 0, 0, berlin)),  // the day before in Berlin
+		booking("x3", "r3", time.Date(2030, 12, 25, 21, 0, 0, 0, newYork)), // the next day in UTC
+		booking("x4", "r1", time.Date(2030, 12, 26, 10, 0, 0, 0, berlin)),
+		booking("x5", "r3", time.Date(2030, 12, 26, 10, 0, 0, 0, newYork)),
+	}}
+	s := &bookingService{rooms: rooms, book: book}
+
+	got, names, err := s.holidayConflicts([]repo.HolidayRow{
+		{Date: "2030-12-25", Name: "Christmas"},
+		{Date: "2030-12-26", Name: "Boxing Day", BuildingID: "b2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, b := range got {
+		ids = append(ids, b.ID+" "+names[b.ID])
+	}
+	if want := []string{"x1 Christmas", "x3 Christmas", "x5 Boxing Day"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+	// one query per holiday and time zone, however many rooms
+	if book.queries != 3 {
+		t.Errorf("made %d queries, want 3", book.queries)
+	}
+}
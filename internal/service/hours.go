@@ -0,0 +1,60 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"studyroom/internal/repo"
+)
+
+// AddRoomHours stores a weekly opening-hours template for the room. One-off
+// windows from SetRoomSchedule still apply on top of it.
+func (s *bookingService) AddRoomHours(roomID string, h repo.OpeningHours) (string, error) {
+	if _, err := s.rooms.GetByID(roomID); err != nil { return "", errors.New("room not found") }
+	if err := validateHours(h); err != nil { return "", err }
+	h.RoomID = roomID
+	return s.rooms.AddHours(h)
+}
+
+func (s *bookingService) ListRoomHours(roomID string) ([]repo.OpeningHours, error) {
+	if _, err := s.rooms.GetByID(roomID); err != nil { return nil, errors.New("room not found") }
+	return s.rooms.ListHours(roomID)
+}
+
+func (s *bookingService) DeleteRoomHours(roomID, hoursID string) error {
+	return s.rooms.DeleteHours(roomID, hoursID)
+}
+
+func validateHours(h repo.OpeningHours) error {
+	from, err := time.Parse(repo.DateLayout, h.EffectiveFrom)
+	if err != nil { return fmt.Errorf("invalid effective_from %q: want YYYY-MM-DD", h.EffectiveFrom) }
+	if h.EffectiveTo != "" {
+		to, err := time.Parse(repo.DateLayout, h.EffectiveTo)
+		if err != nil { return fmt.Errorf("invalid effective_to %q: want YYYY-MM-DD", h.EffectiveTo) }
+		if to.Before(from) { return errors.New("effective_to is before effective_from") }
+	}
+	if len(h.Ranges) == 0 { return errors.New("opening hours need at least one range") }
+	return validateRanges(h.Ranges)
+}
+
+// validateRanges checks weekly ranges for valid times and overlaps.
+func validateRanges(ranges []repo.HoursRange) error {
+	type period struct{ open, close int }
+	days := map[time.Weekday][]period{}
+	for _, rg := range ranges {
+		if rg.Weekday < time.Sunday || rg.Weekday > time.Saturday { return fmt.Errorf("invalid weekday %d", rg.Weekday) }
+		o, c, err := rg.Minutes()
+		if err != nil { return err }
+		if c <= o { return fmt.Errorf("%s %s-%s: close must be after open", rg.Weekday, rg.Open, rg.Close) }
+		days[rg.Weekday] = append(days[rg.Weekday], period{o, c})
+	}
+	for wd, ps := range days {
+		sort.Slice(ps, func(i, j int) bool { return ps[i].open < ps[j].open })
+		for i := 1; i < len(ps); i++ {
+			if ps[i].open < ps[i-1].close { return fmt.Errorf("overlapping ranges on %s", wd) }
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"studyroom/internal/repo"
+)
+
+// ParseHolidayCalendar reads the VEVENTs of an iCalendar (RFC 5545) file as
+// holidays, one per date an event covers. All-day events use their DTEND as
+// an exclusive bound; timed events count for the date they start on.
+// Recurrence rules are not expanded, and an event may span at most
+// maxHolidayEventDays.
+func ParseHolidayCalendar(ics string) ([]repo.HolidayRow, error) {
+	// undo line folding: a line starting with a space or tab continues the previous one
+	ics = strings.ReplaceAll(ics, "\r\n", "\n")
+	ics = strings.ReplaceAll(ics, "\n ", "")
+	ics = strings.ReplaceAll(ics, "\n\t", "")
+
+	var out []repo.HolidayRow
+	var inEvent bool
+	var name, start, end string
+	for i, line := range strings.Split(ics, "\n") {
+		key, val, ok := strings.Cut(line, ":")
+		if !ok { continue }
+		key, _, _ = strings.Cut(key, ";") // drop parameters such as VALUE=DATE
+		switch strings.ToUpper(key) {
+		case "BEGIN":
+			if strings.EqualFold(val, "VEVENT") { inEvent, name, start, end = true, "", "", "" }
+		case "END":
+			if !strings.EqualFold(val, "VEVENT") || !inEvent { continue }
+			inEvent = false
+			days, err := icalDays(start, end)
+			if err != nil { return nil, fmt.Errorf("event ending on line %d: %w", i+1, err) }
+			if name == "" { name = "Holiday" }
+			for _, d := range days { out = append(out, repo.HolidayRow{Date: d, Name: name}) }
+		case "SUMMARY":
+			name = icalUnescape(val)
+		case "DTSTART":
+			start = val
+		case "DTEND":
+			end = val
+		}
+	}
+	if len(out) == 0 { return nil, errors.New("no events found in calendar") }
+	return out, nil
+}
+
+// maxHolidayEventDays bounds one event: every date becomes a holiday that
+// is checked against each room's bookings.
+const maxHolidayEventDays = 92
+
+// icalDays expands DTSTART/DTEND values into YYYY-MM-DD dates.
+func icalDays(start, end string) ([]string, error) {
+	if len(start) < 8 { return nil, fmt.Errorf("missing or invalid DTSTART %q", start) }
+	from, err := time.Parse("20060102", start[:8])
+	if err != nil { return nil, fmt.Errorf("invalid DTSTART %q", start) }
+	to := from.AddDate(0, 0, 1)
+	if len(end) == 8 { // all-day: DTEND is the day after the last one
+		if to, err = time.Parse("20060102", end); err != nil { return nil, fmt.Errorf("invalid DTEND %q", end) }
+	}
+	if to.After(from.AddDate(0, 0, maxHolidayEventDays)) {
+		return nil, fmt.Errorf("event spans more than %d days", maxHolidayEventDays)
+	}
+	var out []string
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) { out = append(out, d.Format(repo.DateLayout)) }
+	if len(out) == 0 { return nil, errors.New("DTEND is not after DTSTART") }
+	return out, nil
+}
+
+var icalEscapes = strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+func icalUnescape(v string) string { return strings.TrimSpace(icalEscapes.Replace(v)) }
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseHolidayCalendar(t *testing.T) {
+	cal := func(lines ...string) string {
+		all := append([]string{"BEGIN:VCALENDAR", "VERSION:2.0"}, lines...)
+		return strings.Join(append(all, "END:VCALENDAR"), "\r\n") + "\r\n"
+	}
+	tests := []struct {
+		name string
+		ics  string
+		want []string // "date name"
+	}{
+		{
+			name: "all-day event",
+			ics:  cal("BEGIN:VEVENT", "SUMMARY:New Year", "DTSTART;VALUE=DATE:20270101", "DTEND;VALUE=DATE:20270102", "END:VEVENT"),
+			want: []string{"2027-01-01 New Year"},
+		},
+		{
+			name: "all-day event without DTEND",
+			ics:  cal("BEGIN:VEVENT", "SUMMARY:Founders' Day", "DTSTART;VALUE=DATE:20261102", "END:VEVENT"),
+			want: []string{"2026-11-02 Founders' Day"},
+		},
+		{
+			name: "multi-day event ends before DTEND",
+			ics:  cal("BEGIN:VEVENT", "SUMMARY:Winter break", "DTSTART;VALUE=DATE:20261230", "DTEND;VALUE=DATE:20270102", "END:VEVENT"),
+			want: []string{"2026-12-30 Winter break", "2026-12-31 Winter break", "2027-01-01 Winter break"},
+		},
+		{
+			name: "timed event counts for its start date only",
+			ics:  cal("BEGIN:VEVENT", "SUMMARY:Open day", "DTSTART:20261107T090000Z", "DTEND:20261108T170000Z", "END:VEVENT"),
+			want: []string{"2026-11-07 Open day"},
+		},
+		{
+			name: "timed event with a zone parameter",
+			ics:  cal("BEGIN:VEVENT", "SUMMARY:Inventory", "DTSTART;TZID=Europe/Berlin:20261120T080000", "END:VEVENT"),
+			want: []string{"2026-11-20 Inventory"},
+		},
+		{
+			name: "folded and escaped summary",
+			ics: "BEGIN:VEVENT\r\nSUMMARY:Day of German\r\n  Unity\\, observed\r\nDTSTART;VALUE=DATE:\r\n\t20261003\r\n" +
+				"END:VEVENT\r\n",
+			want: []string{"2026-10-03 Day of German Unity, observed"},
+		},
+		{
+			name: "several events, unnamed one and bare newlines",
+			ics: "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20261224\nEND:VEVENT\n" +
+				"BEGIN:VEVENT\nsummary:Boxing Day\nDTSTART;VALUE=DATE:20261226\nend:vevent\n",
+			want: []string{"2026-12-24 Holiday", "2026-12-26 Boxing Day"},
+		},
+		{
+			name: "properties outside events are ignored",
+			ics:  cal("SUMMARY:Not an event", "DTSTART:20260101", "BEGIN:VEVENT", "DTSTART;VALUE=DATE:20260501", "END:VEVENT"),
+			want: []string{"2026-05-01 Holiday"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hs, err := ParseHolidayCalendar(tt.ics)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, h := range hs {
+				got = append(got, h.Date+" "+h.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHolidayCalendarErrors(t *testing.T) {
+	event := func(props ...string) string {
+		return strings.Join(append(append([]string{"BEGIN:VEVENT"}, props...), "END:VEVENT"), "\n")
+	}
+	tests := []struct {
+		name, ics, want string
+	}{
+		{"empty", "", "no events"},
+		{"not a calendar", "hello world", "no events"},
+		{"missing DTSTART", event("SUMMARY:x"), "DTSTART"},
+		{"invalid DTSTART", event("DTSTART;VALUE=DATE:2026-10-03"), "DTSTART"},
+		{"invalid DTEND", event("DTSTART;VALUE=DATE:20261003", "DTEND;VALUE=DATE:2026103x"), "DTEND"},
+		{"DTEND before DTSTART", event("DTSTART;VALUE=DATE:20261003", "DTEND;VALUE=DATE:20261001"), "not after"},
+		{"DTEND equal to DTSTART", event("DTSTART;VALUE=DATE:20261003", "DTEND;VALUE=DATE:20261003"), "not after"},
+		{"event spanning years", event("DTSTART;VALUE=DATE:20260101", "DTEND;VALUE=DATE:20360101"), "spans more than"},
+		{"error names the line", "X:1\n" + event("DTSTART:bogus"), "line 4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hs, err := ParseHolidayCalendar(tt.ics)
+			if err == nil {
+				t.Fatalf("got %d holidays, want an error", len(hs))
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not mention %q", err, tt.want)
+			}
+		})
+	}
+
+	// the longest allowed event still parses
+	long := event("DTSTART;VALUE=DATE:20260601", "DTEND;VALUE=DATE:20260901")
+	if hs, err := ParseHolidayCalendar(long); err != nil || len(hs) != maxHolidayEventDays {
+		t.Errorf("a %d-day event gave %d holidays, %v", maxHolidayEventDays, len(hs), err)
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+
+	"studyroom/internal/repo"
+)
+
+func (s *bookingService) CreateCampus(name string) (string, error) {
+	if name == "" { return "", errors.New("campus name must not be empty") }
+	return s.locations.CreateCampus(name)
+}
+
+func (s *bookingService) CreateBuilding(b repo.BuildingRow) (string, error) {
+	if err := validateBuilding(b); err != nil { return "", err }
+	campuses, err := s.locations.ListCampuses()
+	if err != nil { return "", err }
+	for _, c := range campuses {
+		if c.ID == b.CampusID { return s.locations.CreateBuilding(b) }
+	}
+	return "", errors.New("campus not found")
+}
+
+// UpdateBuilding replaces the building's name and location metadata.
+func (s *bookingService) UpdateBuilding(b repo.BuildingRow) (repo.BuildingRow, error) {
+	old, err := s.locations.GetBuilding(b.ID)
+	if err != nil { return repo.BuildingRow{}, errors.New("building not found") }
+	b.CampusID = old.CampusID
+	if err := validateBuilding(b); err != nil { return repo.BuildingRow{}, err }
+	if err := s.locations.UpdateBuilding(b); err != nil { return repo.BuildingRow{}, err }
+	return s.locations.GetBuilding(b.ID)
+}
+
+func (s *bookingService) CreateFloor(buildingID string, level int, name string) (string, error) {
+	if _, err := s.locations.GetBuilding(buildingID); err != nil { return "", errors.New("building not found") }
+	return s.locations.CreateFloor(buildingID, level, name)
+}
+
+func validateBuilding(b repo.BuildingRow) error {
+	if b.Name == "" { return errors.New("building name must not be empty") }
+	if b.Latitude < -90 || b.Latitude > 90 || b.Longitude < -180 || b.Longitude > 180 { return errors.New("invalid coordinates") }
+	if _, err := repo.LoadZone(b.TimeZone); err != nil { return err }
+	return validateRanges(b.Hours)
+}
+
+// checkPlacement makes sure floorID, if set, belongs to buildingID and that
+// the building exists.
+func (s *bookingService) checkPlacement(buildingID, floorID string) error {
+	if buildingID == "" {
+		if floorID != "" { return errors.New("a floor needs a building") }
+		return nil
+	}
+	if _, err := s.locations.GetBuilding(buildingID); err != nil { return errors.New("building not found") }
+	if floorID == "" { return nil }
+	f, err := s.locations.GetFloor(floorID)
+	if err != nil || f.BuildingID != buildingID { return errors.New("floor not found in this building") }
+	return nil
+}
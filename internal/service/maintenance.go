@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunMaintenance runs the periodic booking jobs every interval until ctx is
+// cancelled. It is safe to run on several nodes at once: every state change
+// is a conditional update, so only one node acts on a given booking.
+func RunMaintenance(ctx context.Context, svc BookingService, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if n, err := svc.ReleaseNoShows(); err != nil {
+				log.Printf("no-show release: %v", err)
+			} else if n > 0 {
+				log.Printf("released %d no-show bookings", n)
+			}
+			if n, err := svc.ExpireHolds(); err != nil {
+				log.Printf("hold expiry: %v", err)
+			} else if n > 0 {
+				log.Printf("released %d expired holds", n)
+			}
+		}
+	}
+}
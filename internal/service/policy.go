@@ -0,0 +1,68 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"studyroom/internal/repo"
+)
+
+func validatePolicy(p repo.RoomPolicy) error {
+	if p.MinDurationMin < 0 || p.MaxDurationMin < 0 || p.MaxAdvanceDays < 0 || p.MinNoticeMin < 0 || p.SlotGranularityMin < 0 ||
+		p.BufferBeforeMin < 0 || p.BufferAfterMin < 0 || p.MinOccupancyPct < 0 {
+		return errors.New("policy values must not be negative")
+	}
+	if p.MaxDurationMin > 0 && p.MinDurationMin > p.MaxDurationMin {
+		return errors.New("min duration exceeds max duration")
+	}
+	if p.SlotGranularityMin > 24*60 {
+		return errors.New("slot granularity must be at most one day")
+	}
+	if p.MinOccupancyPct > 100 {
+		return errors.New("minimum occupancy must be at most 100%")
+	}
+	if p.WaitlistMode != "" && p.WaitlistMode != WaitlistOffer && p.WaitlistMode != WaitlistAutoBook {
+		return errors.New("waitlist mode must be offer or auto")
+	}
+	return nil
+}
+
+// checkPartySize reports whether partySize people fit the room and, for
+// rooms with a minimum occupancy, whether they use enough of it.
+func checkPartySize(room repo.RoomRow, partySize int) error {
+	if partySize < 1 { return errors.New("party size must be at least 1") }
+	if partySize > room.Capacity {
+		return fmt.Errorf("party of %d exceeds room capacity of %d", partySize, room.Capacity)
+	}
+	if pct := room.Policy.MinOccupancyPct; pct > 0 {
+		if min := (room.Capacity*pct + 99) / 100; partySize < min {
+			return fmt.Errorf("this room requires a party of at least %d", min)
+		}
+	}
+	return nil
+}
+
+// checkPolicy reports why [st, en) violates the room policy p at time now.
+// st must be in the room's time zone so slot boundaries follow local time.
+func checkPolicy(p repo.RoomPolicy, st, en time.Time, now time.Time) error {
+	d := en.Sub(st)
+	if p.MinDurationMin > 0 && d < time.Duration(p.MinDurationMin)*time.Minute {
+		return fmt.Errorf("bookings in this room must last at least %d minutes", p.MinDurationMin)
+	}
+	if p.MaxDurationMin > 0 && d > time.Duration(p.MaxDurationMin)*time.Minute {
+		return fmt.Errorf("bookings in this room may last at most %d minutes", p.MaxDurationMin)
+	}
+	if p.MaxAdvanceDays > 0 && st.After(now.AddDate(0, 0, p.MaxAdvanceDays)) {
+		return fmt.Errorf("this room cannot be booked more than %d days in advance", p.MaxAdvanceDays)
+	}
+	if p.MinNoticeMin > 0 && st.Before(now.Add(time.Duration(p.MinNoticeMin)*time.Minute)) {
+		return fmt.Errorf("this room must be booked at least %d minutes in advance", p.MinNoticeMin)
+	}
+	if g := p.SlotGranularityMin; g > 0 {
+		if (st.Hour()*60+st.Minute())%g != 0 || st.Second() != 0 || st.Nanosecond() != 0 {
+			return fmt.Errorf("bookings in this room must start on a %d-minute boundary", g)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrQuotaExceeded is wrapped by every error returned when a booking would
+// take a user over one of the limits in BookingConfig.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// QuotaItem is one limit together with how much of it the user has used.
+// Hours are reported as fractional hours.
+type QuotaItem struct {
+	Limit     float64 `json:"limit"`
+	Used      float64 `json:"used"`
+	Remaining float64 `json:"remaining"`
+	Unlimited bool    `json:"unlimited,omitempty"`
+}
+
+type QuotaStatus struct {
+	ActiveBookings QuotaItem `json:"active_bookings"`
+	HoursToday     QuotaItem `json:"hours_today"`
+	HoursThisWeek  QuotaItem `json:"hours_this_week"`
+	Simultaneous   QuotaItem `json:"simultaneous"`
+}
+
+type interval struct{ start, end time.Time }
+
+func (iv interval) overlap(o interval) time.Duration {
+	s, e := iv.start, iv.end
+	if o.start.After(s) { s = o.start }
+	if o.end.Before(e) { e = o.end }
+	if !e.After(s) { return 0 }
+	return e.Sub(s)
+}
+
+// Days and weeks are counted in UTC; weeks start on Monday.
+func dayOf(t time.Time) interval {
+	y, m, d := t.UTC().Date()
+	s := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return interval{s, s.AddDate(0, 0, 1)}
+}
+
+func weekOf(t time.Time) interval {
+	day := dayOf(t).start
+	s := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	return interval{s, s.AddDate(0, 0, 7)}
+}
+
+// userBookings loads the confirmed bookings and live holds of userID that
+// end after from, skipping excludeID, as parsed intervals.
+func (s *bookingService) userBookings(userID string, from time.Time, excludeID string) ([]interval, error) {
+	rows, err := s.book.ListByUser(userID, from)
+	if err != nil { return nil, err }
+	var out []interval
+	for _, b := range rows {
+		if b.ID == excludeID { continue }
+		out = append(out, interval{b.Start, b.End})
+	}
+	return out, nil
+}
+
+// checkQuota reports whether userID may additionally book [st, en).
+// pending holds intervals already accepted earlier in the same operation
+// (e.g. previous occurrences of a series) and excludeID a booking being
+// replaced, such as the one being rescheduled.
+func (s *bookingService) checkQuota(userID string, st, en time.Time, excludeID string, pending []interval) error {
+	c := s.cfg
+	if c.MaxActiveBookings == 0 && c.MaxPerDay == 0 && c.MaxPerWeek == 0 && c.MaxSimultaneous == 0 {
+		return nil
+	}
+	iv := interval{st, en}
+
+	now := time.Now()
+	from := weekOf(st).start
+	if now.Before(from) { from = now }
+	existing, err := s.userBookings(userID, from, excludeID)
+	if err != nil { return err }
+	existing = append(existing, pending...)
+
+	if c.MaxActiveBookings > 0 {
+		active := 0
+		for _, b := range existing {
+			if b.end.After(now) { active++ }
+		}
+		if active+1 > c.MaxActiveBookings {
+			return fmt.Errorf("%w: at most %d upcoming bookings allowed", ErrQuotaExceeded, c.MaxActiveBookings)
+		}
+	}
+	if c.MaxPerDay > 0 {
+		for day := dayOf(st); day.start.Before(en); day = dayOf(day.end) {
+			if booked(existing, day)+iv.overlap(day) > c.MaxPerDay {
+				return fmt.Errorf("%w: at most %s of bookings per day (%s)", ErrQuotaExceeded, c.MaxPerDay, day.start.Format("2006-01-02"))
+			}
+		}
+	}
+	if c.MaxPerWeek > 0 {
+		for week := weekOf(st); week.start.Before(en); week = weekOf(week.end) {
+			if booked(existing, week)+iv.overlap(week) > c.MaxPerWeek {
+				return fmt.Errorf("%w: at most %s of bookings per week", ErrQuotaExceeded, c.MaxPerWeek)
+			}
+		}
+	}
+	if c.MaxSimultaneous > 0 && maxConcurrent(existing, iv)+1 > c.MaxSimultaneous {
+		return fmt.Errorf("%w: at most %d simultaneous bookings allowed", ErrQuotaExceeded, c.MaxSimultaneous)
+	}
+	return nil
+}
+
+func booked(bs []interval, window interval) time.Duration {
+	var total time.Duration
+	for _, b := range bs { total += b.overlap(window) }
+	return total
+}
+
+// maxConcurrent is the largest number of bookings in bs running at the same
+// instant somewhere inside window.
+func maxConcurrent(bs []interval, window interval) int {
+	best := 0
+	points := []time.Time{window.start}
+	for _, b := range bs {
+		if b.overlap(window) > 0 && b.start.After(window.start) { points = append(points, b.start) }
+	}
+	for _, p := range points {
+		n := 0
+		for _, b := range bs {
+			if !b.start.After(p) && b.end.After(p) { n++ }
+		}
+		if n > best { best = n }
+	}
+	return best
+}
+
+func quotaItem(limit, used float64) QuotaItem {
+	if limit <= 0 { return QuotaItem{Used: used, Unlimited: true} }
+	rem := limit - used
+	if rem < 0 { rem = 0 }
+	return QuotaItem{Limit: limit, Used: used, Remaining: rem}
+}
+
+func (s *bookingService) Quota(userID string) (*QuotaStatus, error) {
+	now := time.Now()
+	today, week := dayOf(now), weekOf(now)
+	bs, err := s.userBookings(userID, week.start, "")
+	if err != nil { return nil, err }
+	active := 0
+	for _, b := range bs {
+		if b.end.After(now) { active++ }
+	}
+	return &QuotaStatus{
+		ActiveBookings: quotaItem(float64(s.cfg.MaxActiveBookings), float64(active)),
+		HoursToday:     quotaItem(s.cfg.MaxPerDay.Hours(), booked(bs, today).Hours()),
+		HoursThisWeek:  quotaItem(s.cfg.MaxPerWeek.Hours(), booked(bs, week).Hours()),
+		Simultaneous:   quotaItem(float64(s.cfg.MaxSimultaneous), float64(maxConcurrent(bs, interval{now, now.Add(time.Nanosecond)}))),
+	}, nil
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"studyroom/internal/repo"
+)
+
+// Sort criteria for Search, applied in the order given; ties fall through
+// to the next criterion and finally to the room ID.
+const (
+	SortFit       = "fit"       // smallest room that seats MinCapacity first
+	SortDistance  = "distance"  // closest to NearBuildingID first; rooms without coordinates last
+	SortFavorites = "favorites" // the user's favorite rooms first
+	SortName      = "name"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// SearchQuery is a ranked, paginated FindAvailable. Cursor is the
+// NextCursor of the previous page and only valid with the same SortBy,
+// Filter.MinCapacity and NearBuildingID, which the keys depend on.
+type SearchQuery struct {
+	Filter         repo.RoomFilter
+	Start, End     string
+	SortBy         []string // defaults to fit, then name
+	NearBuildingID string   // required by SortDistance
+	UserID         string   // whose favorites SortFavorites uses
+	PageSize       int      // defaults to 20, at most 100
+	Cursor         string
+}
+
+type SearchPage struct {
+	Rooms      []repo.RoomRow
+	Total      int    // available rooms across all pages
+	NextCursor string // empty on the last page
+}
+
+// sortVal is one criterion's value for a room; numeric criteria use N,
+// names use S.
+type sortVal struct {
+	N float64 `json:"n,omitempty"`
+	S string  `json:"s,omitempty"`
+}
+
+// searchCursor marks the last room of a page by its sort values, so the
+// next page starts after it even if rooms were booked in between.
+type searchCursor struct {
+	Sort        string    `json:"sort"`
+	MinCapacity int       `json:"min_capacity,omitempty"` // fit keys
+	Near        string    `json:"near,omitempty"`         // distance keys
+	Keys        []sortVal `json:"keys"`
+	ID          string    `json:"id"`
+}
+
+func (s *searchService) Search(q SearchQuery) (*SearchPage, error) {
+	if len(q.SortBy) == 0 { q.SortBy = []string{SortFit, SortName} }
+	for _, c := range q.SortBy {
+		switch c {
+		case SortFit, SortFavorites, SortName:
+		case SortDistance:
+			if q.NearBuildingID == "" { return nil, errors.New("sorting by distance needs near_building_id") }
+		default:
+			return nil, fmt.Errorf("unknown sort criterion %q", c)
+		}
+	}
+	if q.PageSize <= 0 { q.PageSize = defaultPageSize }
+	if q.PageSize > maxPageSize { q.PageSize = maxPageSize }
+	spec := strings.Join(q.SortBy, ",")
+	var after *searchCursor
+	if q.Cursor != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+		if err == nil { err = json.Unmarshal(raw, &after) }
+		if err != nil || after == nil || after.Sort != spec || len(after.Keys) != len(q.SortBy) ||
+			after.MinCapacity != q.Filter.MinCapacity || after.Near != q.NearBuildingID {
+			return nil, errors.New("invalid cursor")
+		}
+	}
+
+	rooms, err := s.FindAvailable(q.Filter, q.Start, q.End)
+	if err != nil { return nil, err }
+	keyOf, err := s.sortKeys(q)
+	if err != nil { return nil, err }
+	keys := make(map[string][]sortVal, len(rooms))
+	for _, r := range rooms { keys[r.ID] = keyOf(r) }
+	sort.Slice(rooms, func(i, j int) bool { return less(keys[rooms[i].ID], rooms[i].ID, keys[rooms[j].ID], rooms[j].ID) })
+
+	page := &SearchPage{Total: len(rooms), Rooms: []repo.RoomRow{}}
+	from := 0
+	if after != nil {
+		from = sort.Search(len(rooms), func(i int) bool { return less(after.Keys, after.ID, keys[rooms[i].ID], rooms[i].ID) })
+	}
+	to := from + q.PageSize
+	if to > len(rooms) { to = len(rooms) }
+	page.Rooms = append(page.Rooms, rooms[from:to]...)
+	if to < len(rooms) {
+		last := rooms[to-1]
+		raw, _ := json.Marshal(searchCursor{Sort: spec, MinCapacity: q.Filter.MinCapacity, Near: q.NearBuildingID, Keys: keys[last.ID], ID: last.ID})
+		page.NextCursor = base64.RawURLEncoding.EncodeToString(raw)
+	}
+	return page, nil
+}
+
+// sortKeys loads what the criteria need and returns the key function.
+func (s *searchService) sortKeys(q SearchQuery) (func(repo.RoomRow) []sortVal, error) {
+	favorites := map[string]bool{}
+	coords := map[string][2]float64{}
+	var near [2]float64
+	for _, c := range q.SortBy {
+		switch c {
+		case SortFavorites:
+			if q.UserID == "" { continue }
+			ids, err := s.users.ListFavorites(q.UserID)
+			if err != nil { return nil, err }
+			for _, id := range ids { favorites[id] = true }
+		case SortDistance:
+			nb, err := s.locations.GetBuilding(q.NearBuildingID)
+			if err != nil { return nil, errors.New("building not found") }
+			if nb.Latitude == 0 && nb.Longitude == 0 { return nil, fmt.Errorf("building %s has no coordinates to sort by distance from", nb.ID) }
+			near = [2]float64{nb.Latitude, nb.Longitude}
+			bs, err := s.locations.ListBuildings("")
+			if err != nil { return nil, err }
+			for _, b := range bs {
+				if b.Latitude != 0 || b.Longitude != 0 { coords[b.ID] = [2]float64{b.Latitude, b.Longitude} }
+			}
+		}
+	}
+	return func(r repo.RoomRow) []sortVal {
+		out := make([]sortVal, len(q.SortBy))
+		for i, c := range q.SortBy {
+			switch c {
+			case SortFit:
+				out[i].N = float64(r.Capacity - q.Filter.MinCapacity)
+			case SortDistance:
+				out[i].N = math.MaxFloat64
+				if r.BuildingID == q.NearBuildingID {
+					out[i].N = 0
+				} else if pos, ok := coords[r.BuildingID]; ok {
+					out[i].N = distanceKm(near, pos)
+				}
+			case SortFavorites:
+				if !favorites[r.ID] { out[i].N = 1 }
+			case SortName:
+				out[i].S = strings.ToLower(r.Name)
+			}
+		}
+		return out
+	}, nil
+}
+
+// less orders rooms by their sort values, then by ID.
+func less(a []sortVal, aID string, b []sortVal, bID string) bool {
+	for i := range a {
+		if a[i].N != b[i].N { return a[i].N < b[i].N }
+		if a[i].S != b[i].S { return a[i].S < b[i].S }
+	}
+	return aID < bID
+}
+
+// distanceKm is the great-circle distance between two (lat, lng) points.
+func distanceKm(a, b [2]float64) float64 {
+	const earthRadiusKm = 6371
+	rad := func(d float64) float64 { return d * math.Pi / 180 }
+	dLat, dLng := rad(b[0]-a[0]), rad(b[1]-a[1])
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(rad(a[0]))*math.Cos(rad(b[0]))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+// ListFavorites returns the user's favorite rooms that still exist.
+func (s *searchService) ListFavorites(userID string) ([]repo.RoomRow, error) {
+	ids, err := s.users.ListFavorites(userID)
+	if err != nil { return nil, err }
+	out := []repo.RoomRow{}
+	for _, id := range ids {
+		if r, err := s.rooms.GetByID(id); err == nil { out = append(out, r) }
+	}
+	return out, nil
+}
+
+func (s *searchService) SetFavorite(userID, roomID string, favorite bool) error {
+	if favorite {
+		if _, err := s.rooms.GetByID(roomID); err != nil { return errors.New("room not found") }
+	}
+	return s.users.SetFavorite(userID, roomID, favorite)
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"studyroom/internal/repo"
+)
+
+func TestLess(t *testing.T) {
+	n := func(v ...float64) []sortVal {
+		out := make([]sortVal, len(v))
+		for i := range v {
+			out[i].N = v[i]
+		}
+		return out
+	}
+	tests := []struct {
+		name     string
+		a        []sortVal
+		aID      string
+		b        []sortVal
+		bID      string
+		wantLess bool
+	}{
+		{"first key decides", n(1, 9), "z", n(2, 0), "a", true},
+		{"tie falls through to the next key", n(1, 2), "z", n(1, 3), "a", true},
+		{"names compare as strings", []sortVal{{S: "apple"}}, "z", []sortVal{{S: "banana"}}, "a", true},
+		{"full tie falls back to the ID", n(1, 2), "a", n(1, 2), "b", true},
+		{"equal is not less", n(1, 2), "a", n(1, 2), "a", false},
+		{"greater", n(3), "a", n(2), "b", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := less(tt.a, tt.aID, tt.b, tt.bID); got != tt.wantLess {
+				t.Errorf("less = %v, want %v", got, tt.wantLess)
+			}
+		})
+	}
+}
+
+// newSearchService serves nine rooms with ties on capacity and on name, in
+// buildings at known distances along the equator.
+func newSearchService() (*searchService, *fakeRooms) {
+	rooms := &fakeRooms{rooms: map[string]repo.RoomRow{}}
+	add := func(id, name string, capacity int, building string) {
+		rooms.rooms[id] = repo.RoomRow{ID: id, Name: name, Capacity: capacity, BuildingID: building}
+	}
+	add("r1", "Alder", 4, "b1")
+	add("r2", "Birch", 4, "b2")
+	add("r3", "Cedar", 4, "b3")
+	add("r4", "alder", 6, "b1")
+	add("r5", "Elm", 6, "b4")
+	add("r6", "Fir", 8, "b2")
+	add("r7", "Gum", 4, "b3")
+	add("r8", "Hazel", 6, "")
+	add("r9", "Ivy", 10, "b1")
+	locs := &fakeLocations{buildings: []repo.BuildingRow{
+		{ID: "b1", Latitude: 0.001, Longitude: 0},
+		{ID: "b2", Latitude: 0.001, Longitude: 1},
+		{ID: "b3", Latitude: 0.001, Longitude: 2},
+		{ID: "b4"}, // no coordinates
+	}}
+	return &searchService{rooms: rooms, locations: locs, users: &fakeUsers{favorites: []string{"r5", "r9"}}}, rooms
+}
+
+// inSlot sets the slot searched; the fake returns every room for any slot.
+func inSlot(q SearchQuery) SearchQuery {
+	q.Start, q.End = "2030-01-07T10:00:00Z", "2030-01-07T11:00:00Z"
+	return q
+}
+
+// searchAll pages through q and returns the room IDs in order.
+func searchAll(t *testing.T, s *searchService, q SearchQuery) []string {
+	t.Helper()
+	var ids []string
+	for page := 0; ; page++ {
+		if page > 20 {
+			t.Fatal("pagination does not end")
+		}
+		res, err := s.Search(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Total != 9 {
+			t.Errorf("page %d: total %d, want 9", page, res.Total)
+		}
+		for _, r := range res.Rooms {
+			ids = append(ids, r.ID)
+		}
+		if res.NextCursor == "" {
+			return ids
+		}
+		if len(res.Rooms) != q.PageSize {
+			t.Errorf("page %d has %d rooms, want a full page of %d", page, len(res.Rooms), q.PageSize)
+		}
+		q.Cursor = res.NextCursor
+	}
+}
+
+func TestSearchPagination(t *testing.T) {
+	tests := []struct {
+		name string
+		q    SearchQuery
+		want []string
+	}{
+		{
+			name: "default sort: fit, then name, then ID",
+			q:    SearchQuery{Filter: repo.RoomFilter{MinCapacity: 4}},
+			want: []string{"r1", "r2", "r3", "r7", "r4", "r5", "r8", "r6", "r9"},
+		},
+		{
+			name: "names tie case-insensitively and fall back to the ID",
+			q:    SearchQuery{SortBy: []string{SortName}},
+			want: []string{"r1", "r4", "r2", "r3", "r5", "r6", "r7", "r8", "r9"},
+		},
+		{
+			name: "favorites first",
+			q:    SearchQuery{SortBy: []string{SortFavorites, SortFit}, UserID: "u1"},
+			want: []string{"r5", "r9", "r1", "r2", "r3", "r7", "r4", "r8", "r6"},
+		},
+		{
+			name: "distance, rooms without coordinates last",
+			q:    SearchQuery{SortBy: []string{SortDistance}, NearBuildingID: "b2"},
+			want: []string{"r2", "r6", "r1", "r3", "r4", "r7", "r9", "r5", "r8"},
+		},
+	}
+	for _, tt := range tests {
+		for _, size := range []int{1, 2, 3, 4, 9, 50} {
+			t.Run(fmt.Sprintf("%s/page size %d", tt.name, size), func(t *testing.T) {
+				s, _ := newSearchService()
+				q := inSlot(tt.q)
+				q.PageSize = size
+				if got := searchAll(t, s, q); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestSearchResumesAfterChanges(t *testing.T) {
+	s, rooms := newSearchService()
+	q := inSlot(SearchQuery{PageSize: 3})
+	first, err := s.Search(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the last room of the page and the first of the next are booked
+	// meanwhile; the next page starts after the cursor all the same
+	delete(rooms.rooms, "r3")
+	delete(rooms.rooms, "r7")
+	q.Cursor = first.NextCursor
+	next, err := s.Search(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range next.Rooms {
+		got = append(got, r.ID)
+	}
+	if want := []string{"r4", "r5", "r8"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("next page %v, want %v", got, want)
+	}
+}
+
+func TestSearchCursorRejected(t *testing.T) {
+	s, _ := newSearchService()
+	q := inSlot(SearchQuery{Filter: repo.RoomFilter{MinCapacity: 4}, SortBy: []string{SortDistance, SortFit}, NearBuildingID: "b1", PageSize: 2})
+	page, err := s.Search(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := base64.RawURLEncoding.DecodeString(page.NextCursor)
+	var c searchCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		t.Fatal(err)
+	}
+	encode := func(c searchCursor) string {
+		raw, _ := json.Marshal(c)
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+	fewerKeys := c
+	fewerKeys.Keys = c.Keys[:1]
+
+	tests := []struct {
+		name   string
+		cursor string
+		change func(*SearchQuery)
+	}{
+		{"not base64", "!!!", nil},
+		{"not JSON", base64.RawURLEncoding.EncodeToString([]byte("{")), nil},
+		{"null", base64.RawURLEncoding.EncodeToString([]byte("null")), nil},
+		{"wrong number of keys", encode(fewerKeys), nil},
+		{"different sort", page.NextCursor, func(q *SearchQuery) { q.SortBy = []string{SortFit, SortDistance} }},
+		{"default sort", page.NextCursor, func(q *SearchQuery) { q.SortBy = nil }},
+		{"different min capacity", page.NextCursor, func(q *SearchQuery) { q.Filter.MinCapacity = 6 }},
+		{"different reference building", page.NextCursor, func(q *SearchQuery) { q.NearBuildingID = "b2" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := q
+			q.Cursor = tt.cursor
+			if tt.change != nil {
+				tt.change(&q)
+			}
+			if _, err := s.Search(q); err == nil || !strings.Contains(err.Error(), "invalid cursor") {
+				t.Errorf("got %v, want invalid cursor", err)
+			}
+		})
+	}
+
+	// the unchanged query accepts its own cursor
+	q.Cursor = page.NextCursor
+	if _, err := s.Search(q); err != nil {
+		t.Errorf("own cursor rejected: %v", err)
+	}
+}
+
+func TestSearchQueryErrors(t *testing.T) {
+	s, _ := newSearchService()
+	tests := []struct {
+		name, want string
+		q          SearchQuery
+	}{
+		{"unknown criterion", "unknown sort", SearchQuery{SortBy: []string{"price"}}},
+		{"distance without a building", "near_building_id", SearchQuery{SortBy: []string{SortDistance}}},
+		{"unknown building", "not found", SearchQuery{SortBy: []string{SortDistance}, NearBuildingID: "b9"}},
+		{"building without coordinates", "no coordinates", SearchQuery{SortBy: []string{SortDistance}, NearBuildingID: "b4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Search(inSlot(tt.q)); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RecurrenceRule is the subset of RFC 5545 RRULE we support: FREQ=DAILY or
+// WEEKLY with INTERVAL and BYDAY, bounded by UNTIL or COUNT, plus EXDATEs.
+type RecurrenceRule struct {
+	Freq     string   // "daily" or "weekly"
+	Interval int      // every N days/weeks; 0 means 1, at most 52
+	ByDay    []string // weekly only, "MO".."SU"; empty means the weekday of the first occurrence
+	Until    string   // last occurrence date (inclusive), YYYY-MM-DD or RFC3339
+	Count    int      // occurrences to generate before exception dates are removed
+	ExDates  []string // YYYY-MM-DD dates to skip
+}
+
+// Bounds for a single series: maxOccurrences is a year of daily bookings,
+// and the last occurrence falls within maxSeriesDays of the first.
+const (
+	maxOccurrences = 366
+	maxInterval    = 52
+	maxSeriesDays  = 2 * 366
+)
+
+type occurrence struct{ Start, End time.Time }
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// expandRecurrence turns a first occurrence [st, en) and a rule into the
+// list of occurrences, keeping the wall-clock time of st in its location.
+func expandRecurrence(st, en time.Time, rule RecurrenceRule) ([]occurrence, error) {
+	dur := en.Sub(st)
+	if dur <= 0 { return nil, errors.New("invalid time range") }
+	if dur > 24*time.Hour { return nil, errors.New("recurring bookings cannot be longer than 24h") }
+
+	interval := rule.Interval
+	if interval == 0 { interval = 1 }
+	if interval < 0 || rule.Count < 0 { return nil, errors.New("invalid recurrence") }
+	if interval > maxInterval { return nil, fmt.Errorf("interval must be at most %d", maxInterval) }
+	if rule.Count == 0 && rule.Until == "" {
+		return nil, errors.New("recurrence needs an until date or a count")
+	}
+
+	var until time.Time
+	if rule.Until != "" {
+		if d, err := time.ParseInLocation("2006-01-02", rule.Until, st.Location()); err == nil {
+			until = d.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		} else if t, err := time.Parse(time.RFC3339, rule.Until); err == nil {
+			until = t
+		} else {
+			return nil, errors.New("invalid until date")
+		}
+	}
+
+	days := map[time.Weekday]bool{}
+	switch strings.ToLower(rule.Freq) {
+	case "daily":
+	case "weekly":
+		for _, code := range rule.ByDay {
+			wd, ok := weekdayCodes[strings.ToUpper(code)]
+			if !ok { return nil, errors.New("invalid weekday " + code) }
+			days[wd] = true
+		}
+		if len(days) == 0 { days[st.Weekday()] = true }
+	default:
+		return nil, errors.New("freq must be daily or weekly")
+	}
+
+	skip := map[string]bool{}
+	for _, d := range rule.ExDates {
+		if _, err := time.Parse("2006-01-02", d); err != nil { return nil, errors.New("invalid exception date " + d) }
+		skip[d] = true
+	}
+
+	// weeks start on Monday, as with the RFC 5545 default WKST
+	weekOffset := (int(st.Weekday()) + 6) % 7
+	var out []occurrence
+	generated := 0
+	for i := 0; ; i++ {
+		day := st.AddDate(0, 0, i)
+		occStart := time.Date(day.Year(), day.Month(), day.Day(), st.Hour(), st.Minute(), st.Second(), 0, st.Location())
+		if !until.IsZero() && occStart.After(until) { break }
+		if rule.Count > 0 && generated >= rule.Count { break }
+		if i > maxSeriesDays { return nil, fmt.Errorf("recurrence must end within %d days of the first occurrence", maxSeriesDays) }
+
+		var match bool
+		if len(days) == 0 {
+			match = i%interval == 0
+		} else {
+			match = days[day.Weekday()] && ((i+weekOffset)/7)%interval == 0
+		}
+		if !match { continue }
+
+		generated++
+		if generated > maxOccurrences { return nil, errors.New("recurrence produces too many occurrences") }
+		if skip[occStart.Format("2006-01-02")] { continue }
+		out = append(out, occurrence{Start: occStart, End: occStart.Add(dur)})
+	}
+	if len(out) == 0 { return nil, errors.New("recurrence produces no occurrences") }
+	return out, nil
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"studyroom/internal/repo"
+)
+
+func TestExpandRecurrence(t *testing.T) {
+	berlin := mustZone(t, "Europe/Berlin")
+	// Monday 5 January 2026, 09:00-10:00
+	monday := time.Date(2026, 1, 5, 9, 0, 0, 0, berlin)
+	tests := []struct {
+		name  string
+		start time.Time
+		rule  RecurrenceRule
+		want  []string // local starts, "2006-01-02 15:04"
+	}{
+		{
+			name:  "daily by count",
+			start: monday,
+			rule:  RecurrenceRule{Freq: "daily", Count: 3},
+			want:  []string{"2026-01-05 09:00", "2026-01-06 09:00", "2026-01-07 09:00"},
+		},
+		{
+			name:  "every other day until a date, inclusive",
+			start: monday,
+			rule:  RecurrenceRule{Freq: "DAILY", Interval: 2, Until: "2026-01-09"},
+			want:  []string{"2026-01-05 09:00", "2026-01-07 09:00", "2026-01-09 09:00"},
+		},
+		{
+			name:  "weekly on the start's weekday",
+			start: monday,
+			rule:  RecurrenceRule{Freq: "weekly", Count: 3},
+			want:  []string{"2026-01-05 09:00", "2026-01-12 09:00", "2026-01-19 09:00"},
+		},
+		{
+			name:  "weekly by weekday",
+			start: monday,
+			rule:  RecurrenceRule{Freq: "weekly", ByDay: []string{"MO", "we", "FR"}, Count: 5},
+			want:  []string{"2026-01-05 09:00", "2026-01-07 09:00", "2026-01-09 09:00", "2026-01-12 09:00", "2026-01-14 09:00"},
+		},
+		{
+			name:  "fortnightly by weekday starting mid-week",
+			start: monday.AddDate(0, 0, 2), // Wednesday
+			rule:  RecurrenceRule{Freq: "weekly", Interval: 2, ByDay: []string{"TU", "TH"}, Until: "2026-01-31"},
+			want:  []string{"2026-01-08 09:00", "2026-01-20 09:00", "2026-01-22 09:00"},
+		},
+		{
+			name:  "until ends it before count",
+			start: monday,
+			rule:  RecurrenceRule{Freq: "daily", Count: 10, Until: "2026-01-06"},
+			want:  []string{"2026-01-05 09:00", "2026-01-06 09:00"},
+		},
+		{
+			name:  "count ends it before until",
+			start: monday,
+			rule:  RecurrenceRule{Freq: "daily", Count: 2, Until: "2026-01-31"},
+			want:  []string{"2026-01-05 09:00", "2026-01-06 09:00"},
+		},
+		{
+			name:  "until as a timestamp",
+			start: monday,
+			rule:  RecurrenceRule{Freq: "daily", Until: "2026-01-07T08:00:00Z"},
+			want:  []string{"2026-01-05 09:00", "2026-01-06 09:00", "2026-01-07 09:00"},
+		},
+		{
+			name:  "exception dates count towards count",
+			start: monday,
+			rule:  RecurrenceRule{Freq: "daily", Count: 4, ExDates: []string{"2026-01-06", "2026-01-08"}},
+			want:  []string{"2026-01-05 09:00", "2026-01-07 09:00"},
+		},
+		{
+			name:  "daily across spring forward keeps the local time",
+			start: time.Date(2026, 3, 28, 9, 0, 0, 0, berlin),
+			rule:  RecurrenceRule{Freq: "daily", Count: 3},
+			want:  []string{"2026-03-28 09:00", "2026-03-29 09:00", "2026-03-30 09:00"},
+		},
+		{
+			name:  "weekly across fall back keeps the local time",
+			start: time.Date(2026, 10, 19, 18, 30, 0, 0, berlin),
+			rule:  RecurrenceRule{Freq: "weekly", Until: "2026-11-02"},
+			want:  []string{"2026-10-19 18:30", "2026-10-26 18:30", "2026-11-02 18:30"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			occs, err := expandRecurrence(tt.start, tt.start.Add(time.Hour), tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, o := range occs {
+				got = append(got, o.Start.Format("2006-01-02 15:04"))
+				if o.Start.Location() != berlin {
+					t.Errorf("occurrence %s is in %s, want the start's zone", o.Start, o.Start.Location())
+				}
+				if d := o.End.Sub(o.Start); d != time.Hour {
+					t.Errorf("occurrence %s lasts %s, want 1h", o.Start, d)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandRecurrenceDSTInstants(t *testing.T) {
+	berlin := mustZone(t, "Europe/Berlin")
+	start := time.Date(2026, 3, 28, 9, 0, 0, 0, berlin)
+	occs, err := expandRecurrence(start, start.Add(time.Hour), RecurrenceRule{Freq: "daily", Count: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 09:00 CET is 08:00 UTC, 09:00 CEST the next day is 07:00 UTC
+	if h := occs[0].Start.UTC().Hour(); h != 8 {
+		t.Errorf("first occurrence at %02d:00 UTC, want 08:00", h)
+	}
+	if h := occs[1].Start.UTC().Hour(); h != 7 {
+		t.Errorf("second occurrence at %02d:00 UTC, want 07:00", h)
+	}
+	if gap := occs[1].Start.Sub(occs[0].Start); gap != 23*time.Hour {
+		t.Errorf("occurrences %s apart, want 23h", gap)
+	}
+}
+
+func TestExpandRecurrenceErrors(t *testing.T) {
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		end  time.Time
+		rule RecurrenceRule
+	}{
+		{"no bound", start.Add(time.Hour), RecurrenceRule{Freq: "daily"}},
+		{"unknown freq", start.Add(time.Hour), RecurrenceRule{Freq: "monthly", Count: 2}},
+		{"unknown weekday", start.Add(time.Hour), RecurrenceRule{Freq: "weekly", ByDay: []string{"XX"}, Count: 2}},
+		{"negative interval", start.Add(time.Hour), RecurrenceRule{Freq: "daily", Interval: -1, Count: 2}},
+		{"bad until", start.Add(time.Hour), RecurrenceRule{Freq: "daily", Until: "next week"}},
+		{"bad exception date", start.Add(time.Hour), RecurrenceRule{Freq: "daily", Count: 2, ExDates: []string{"5/1/2026"}}},
+		{"empty range", start, RecurrenceRule{Freq: "daily", Count: 2}},
+		{"longer than a day", start.Add(25 * time.Hour), RecurrenceRule{Freq: "daily", Count: 2}},
+		{"until before start", start.Add(time.Hour), RecurrenceRule{Freq: "daily", Until: "2026-01-04"}},
+		{"every occurrence excluded", start.Add(time.Hour), RecurrenceRule{Freq: "daily", Count: 1, ExDates: []string{"2026-01-05"}}},
+		{"too many", start.Add(time.Hour), RecurrenceRule{Freq: "daily", Count: maxOccurrences + 1}},
+		{"huge interval", start.Add(time.Hour), RecurrenceRule{Freq: "daily", Interval: 1000000000, Count: 2}},
+		{"interval past the limit", start.Add(time.Hour), RecurrenceRule{Freq: "weekly", Interval: maxInterval + 1, Count: 2}},
+		{"occurrences too far ahead", start.Add(time.Hour), RecurrenceRule{Freq: "weekly", Interval: maxInterval, Count: 4}},
+		{"until too far ahead", start.Add(time.Hour), RecurrenceRule{Freq: "weekly", Interval: 10, Until: "2036-01-05"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if occs, err := expandRecurrence(start, tt.end, tt.rule); err == nil {
+				t.Errorf("got %d occurrences, want an error", len(occs))
+			}
+		})
+	}
+}
+
+func TestCancelSeriesTruncatesRule(t *testing.T) {
+	berlin := mustZone(t, "Europe/Berlin")
+	// a daily series of six whose first three occurrences have passed
+	first := time.Now().In(berlin).Add(time.Hour).Truncate(time.Minute).AddDate(0, 0, -3)
+	occs, err := expandRecurrence(first, first.Add(time.Hour), RecurrenceRule{Freq: "daily", Count: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newService := func() (*bookingService, *fakeBookings, *fakeSeries) {
+		book := &fakeBookings{}
+		for _, o := range occs {
+			book.insert(repo.BookingRow{RoomID: "r1", UserID: "u1", SeriesID: "s1", Start: o.Start, End: o.End, Status: "confirmed"})
+		}
+		ser := &fakeSeries{row: repo.SeriesRow{ID: "s1", RoomID: "r1", UserID: "u1", Start: first.UTC(), End: first.Add(time.Hour).UTC(), Freq: "daily", Interval: 1, Count: 6, Status: "active"}}
+		rooms := &fakeRooms{rooms: map[string]repo.RoomRow{"r1": {ID: "r1", TimeZone: "Europe/Berlin"}}}
+		return &bookingService{rooms: rooms, book: book, wait: &fakeWaitlist{}, series: ser}, book, ser
+	}
+
+	t.Run("this and following", func(t *testing.T) {
+		s, book, ser := newService()
+		n, err := s.CancelSeries("s1", "u1", book.rows[4].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 2 {
+			t.Errorf("cancelled %d occurrences, want 2", n)
+		}
+		want := occs[3].Start.Format(repo.DateLayout)
+		if ser.row.Until != want || ser.row.Count != 0 || ser.row.Status != "active" {
+			t.Errorf("series until=%q count=%d status=%s, want until=%q count=0 active", ser.row.Until, ser.row.Count, ser.row.Status, want)
+		}
+		kept, err := expandRecurrence(first, first.Add(time.Hour), RecurrenceRule{Freq: "daily", Until: ser.row.Until})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(kept) != 4 {
+			t.Errorf("truncated rule expands to %d occurrences, want the 4 kept", len(kept))
+		}
+	})
+
+	t.Run("from the first upcoming occurrence", func(t *testing.T) {
+		s, book, ser := newService()
+		n, err := s.CancelSeries("s1", "u1", book.rows[3].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 3 || ser.row.Until != occs[2].Start.Format(repo.DateLayout) {
+			t.Errorf("cancelled %d, until %q; want 3 and %q", n, ser.row.Until, occs[2].Start.Format(repo.DateLayout))
+		}
+	})
+
+	t.Run("whole series", func(t *testing.T) {
+		s, _, ser := newService()
+		if _, err := s.CancelSeries("s1", "u1", ""); err != nil {
+			t.Fatal(err)
+		}
+		if ser.row.Status != "cancelled" || ser.row.Count != 6 {
+			t.Errorf("series status=%s count=%d, want cancelled with its rule intact", ser.row.Status, ser.row.Count)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"studyroom/internal/repo"
+)
+
+// ArchiveKeep lets an archived room's upcoming bookings go ahead. ArchiveRoom
+// also takes ClosureReject (the default) and ClosureCancel.
+const ArchiveKeep = "keep"
+
+// UpdateRoom writes the fields of u named in mask ("name", "capacity",
+// "time_zone", "building_id", "floor_id"). Moving a room to another building
+// without naming a floor takes it off its old floor.
+func (s *bookingService) UpdateRoom(roomID string, u repo.RoomUpdate, mask []string) (repo.RoomRow, error) {
+	room, err := s.rooms.GetByID(roomID)
+	if err != nil { return repo.RoomRow{}, errors.New("room not found") }
+	if len(mask) == 0 { return repo.RoomRow{}, errors.New("update mask is empty") }
+	inMask := map[string]bool{}
+	for _, m := range mask { inMask[m] = true }
+	if inMask["building_id"] && !inMask["floor_id"] && u.BuildingID != room.BuildingID {
+		u.FloorID = ""
+		mask = append(mask, "floor_id")
+		inMask["floor_id"] = true
+	}
+	for _, m := range mask {
+		switch m {
+		case "name":
+			if u.Name == "" { return repo.RoomRow{}, errors.New("name must not be empty") }
+		case "capacity":
+			if u.Capacity <= 0 { return repo.RoomRow{}, errors.New("capacity must be positive") }
+		case "time_zone":
+			if _, err := repo.LoadZone(u.TimeZone); err != nil { return repo.RoomRow{}, err }
+		case "building_id", "floor_id":
+		default:
+			return repo.RoomRow{}, fmt.Errorf("unknown field %q in update mask", m)
+		}
+	}
+	if inMask["building_id"] || inMask["floor_id"] {
+		if !inMask["building_id"] { u.BuildingID = room.BuildingID }
+		if !inMask["floor_id"] { u.FloorID = room.FloorID }
+		if err := s.checkPlacement(u.BuildingID, u.FloorID); err != nil { return repo.RoomRow{}, err }
+	}
+	if err := s.rooms.Update(roomID, u, mask); err != nil { return repo.RoomRow{}, err }
+	return s.rooms.GetByID(roomID)
+}
+
+// ArchiveRoom hides the room from search and closes it to new bookings and
+// waitlist entries; users still queued for the room are dropped from the
+// waitlist and told so. mode decides what happens to bookings that have not
+// ended yet.
+func (s *bookingService) ArchiveRoom(roomID, mode, reason string) (*ScheduleResult, error) {
+	room, err := s.rooms.GetByID(roomID)
+	if err != nil { return nil, errors.New("room not found") }
+	if room.Archived { return nil, errors.New("room is already archived") }
+	switch mode {
+	case "", ClosureReject:
+		upcoming, err := s.book.ListByRoom(roomID, time.Now())
+		if err != nil { return nil, err }
+		if len(upcoming) > 0 {
+			return &ScheduleResult{Conflicts: s.localize(upcoming)}, fmt.Errorf("room has %d upcoming bookings", len(upcoming))
+		}
+	case ClosureCancel, ArchiveKeep:
+	default:
+		return nil, fmt.Errorf("unknown archive mode %q", mode)
+	}
+	if err := s.rooms.SetArchived(roomID, true); err != nil { return nil, err }
+	dropped, err := s.wait.DeleteByRoom(roomID)
+	if err != nil { return nil, err }
+	for _, w := range dropped {
+		s.notify(w.UserID, "waitlist_removed", fmt.Sprintf("Room %s has been taken out of service; your waitlist entry for %s to %s was removed",
+			roomID, w.Start.In(room.Location()).Format(time.RFC3339), w.End.In(room.Location()).Format(time.RFC3339)))
+	}
+
+	res := &ScheduleResult{}
+	if mode != ClosureCancel { return res, nil }
+	upcoming, err := s.book.ListByRoom(roomID, time.Now())
+	if err != nil { return res, err }
+	if reason == "" { reason = "the room has been taken out of service" }
+	for _, b := range s.localize(upcoming) {
+		ok, err := s.cancelForClosure(&b, reason)
+		if err != nil { return res, err }
+		if ok { res.Cancelled = append(res.Cancelled, b) }
+	}
+	return res, nil
+}
+
+// RestoreRoom brings an archived room back into service.
+func (s *bookingService) RestoreRoom(roomID string) error {
+	room, err := s.rooms.GetByID(roomID)
+	if err != nil { return errors.New("room not found") }
+	if !room.Archived { return errors.New("room is not archived") }
+	return s.rooms.SetArchived(roomID, false)
+}
+
+// DeleteRoom removes a room that has never been booked; rooms with booking
+// history can only be archived.
+func (s *bookingService) DeleteRoom(roomID string) error {
+	if _, err := s.rooms.GetByID(roomID); err != nil { return errors.New("room not found") }
+	used, err := s.book.HasHistory(roomID)
+	if err != nil { return err }
+	if used { return errors.New("room has booking history; archive it instead") }
+	return s.rooms.Delete(roomID)
+}
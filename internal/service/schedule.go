@@ -0,0 +1,105 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"studyroom/internal/repo"
+)
+
+func (s *bookingService) ListRoomSchedule(roomID string) ([]repo.ScheduleWindow, error) {
+	room, err := s.rooms.GetByID(roomID)
+	if err != nil { return nil, errors.New("room not found") }
+	ws, err := s.rooms.ListSchedule(roomID)
+	if err != nil { return nil, err }
+	for i := range ws { ws[i].Start, ws[i].End = ws[i].Start.In(room.Location()), ws[i].End.In(room.Location()) }
+	return ws, nil
+}
+
+func (s *bookingService) GetRoomSchedule(roomID, windowID string) (repo.ScheduleWindow, error) {
+	room, err := s.rooms.GetByID(roomID)
+	if err != nil { return repo.ScheduleWindow{}, errors.New("room not found") }
+	w, err := s.window(roomID, windowID)
+	if err != nil { return repo.ScheduleWindow{}, err }
+	w.Start, w.End = w.Start.In(room.Location()), w.End.In(room.Location())
+	return w, nil
+}
+
+// UpdateRoomSchedule moves a window to [start, end). A closed window may
+// not grow over active bookings. Bookings that an open window no longer
+// covers stay as they are and are reported as orphaned.
+func (s *bookingService) UpdateRoomSchedule(roomID, windowID string, start, end string) (*ScheduleResult, error) {
+	w, err := s.window(roomID, windowID)
+	if err != nil { return nil, err }
+	_, st, en, err := s.roomRange(roomID, start, end)
+	if err != nil { return nil, err }
+	if err := s.checkWindowOverlap(roomID, st, en, w.IsOpen, w.ID); err != nil { return nil, err }
+	if !w.IsOpen {
+		if res, err := s.closureConflicts(roomID, st, en); err != nil { return res, err }
+	}
+	if err := s.rooms.UpdateSchedule(w.ID, st, en); err != nil { return nil, err }
+
+	res := &ScheduleResult{WindowID: w.ID}
+	if w.IsOpen {
+		if res.Orphaned, err = s.orphans(roomID, w.Start, w.End); err != nil { return res, err }
+		s.promoteWaitlist(roomID, st, en)
+	} else {
+		// whatever the closure no longer covers is bookable again
+		s.promoteWaitlist(roomID, w.Start, w.End)
+	}
+	return res, nil
+}
+
+// DeleteRoomSchedule removes a window. Deleting an open window reports the
+// bookings left outside open time; deleting a closure frees its interval.
+func (s *bookingService) DeleteRoomSchedule(roomID, windowID string) (*ScheduleResult, error) {
+	w, err := s.window(roomID, windowID)
+	if err != nil { return nil, err }
+	if err := s.rooms.DeleteSchedule(w.ID); err != nil { return nil, err }
+	res := &ScheduleResult{WindowID: w.ID}
+	if w.IsOpen {
+		res.Orphaned, err = s.orphans(roomID, w.Start, w.End)
+		return res, err
+	}
+	s.promoteWaitlist(roomID, w.Start, w.End)
+	return res, nil
+}
+
+// window loads a schedule window and checks that it belongs to roomID.
+func (s *bookingService) window(roomID, windowID string) (repo.ScheduleWindow, error) {
+	w, err := s.rooms.GetSchedule(windowID)
+	if err != nil || w.RoomID != roomID { return repo.ScheduleWindow{}, errors.New("schedule window not found") }
+	return w, nil
+}
+
+func (s *bookingService) checkWindowOverlap(roomID string, start, end time.Time, isOpen bool, excludeID string) error {
+	over, err := s.rooms.HasScheduleOverlap(roomID, start, end, isOpen, excludeID)
+	if err != nil { return err }
+	if !over { return nil }
+	if isOpen { return errors.New("overlaps another open window of this room") }
+	return errors.New("overlaps another closed window of this room")
+}
+
+// closureConflicts refuses a closure of [start, end) while active bookings
+// fall into it; the result lists them.
+func (s *bookingService) closureConflicts(roomID string, start, end time.Time) (*ScheduleResult, error) {
+	conflicts, err := s.book.ListOccupying(roomID, start, end)
+	if err != nil { return nil, err }
+	if len(conflicts) == 0 { return nil, nil }
+	return &ScheduleResult{Conflicts: s.localize(conflicts)}, fmt.Errorf("closure overlaps %d active bookings", len(conflicts))
+}
+
+// orphans lists the active bookings in [start, end) that the room's open
+// time no longer covers.
+func (s *bookingService) orphans(roomID string, start, end time.Time) ([]repo.BookingRow, error) {
+	bs, err := s.book.ListOccupying(roomID, start, end)
+	if err != nil { return nil, err }
+	var out []repo.BookingRow
+	for _, b := range bs {
+		ok, err := s.rooms.IsWithinOpenSchedule(roomID, b.Start, b.End)
+		if err != nil { return nil, err }
+		if !ok { out = append(out, b) }
+	}
+	return s.localize(out), nil
+}
@@ -1,17 +1,64 @@
 package service
 
-import "studyroom/internal/repo"
+import (
+	"errors"
+	"time"
+
+	"studyroom/internal/repo"
+)
 
 type SearchService interface {
-	FindAvailable(minCapacity int, start, end string) ([]repo.RoomRow, error)
+	FindAvailable(f repo.RoomFilter, start, end string) ([]repo.RoomRow, error)
+	ListAmenities() ([]repo.AmenityRow, error)
+	ListCampuses() ([]repo.CampusRow, error)
+	ListBuildings(campusID string) ([]repo.BuildingRow, error)
+	GetBuilding(buildingID string) (repo.BuildingRow, error)
+	ListFloors(buildingID string) ([]repo.FloorRow, error)
+	FindNextSlots(q SlotQuery) ([]Slot, error)
+	Search(q SearchQuery) (*SearchPage, error)
+	ListFavorites(userID string) ([]repo.RoomRow, error)
+	SetFavorite(userID, roomID string, favorite bool) error
+}
+
+type searchService struct {
+	rooms     repo.RoomRepo
+	book      repo.BookingRepo
+	amenities repo.AmenityRepo
+	locations repo.LocationRepo
+	users     repo.UserRepo
+}
+
+func NewSearchService(r repo.RoomRepo, b repo.BookingRepo, a repo.AmenityRepo, l repo.LocationRepo, u repo.UserRepo) SearchService {
+	return &searchService{rooms: r, book: b, amenities: a, locations: l, users: u}
 }
 
-type searchService struct{ rooms repo.RoomRepo }
+// FindAvailable reads times without an offset as UTC, since the search spans
+// rooms in any time zone. Rooms must have every amenity listed.
+func (s *searchService) FindAvailable(f repo.RoomFilter, start, end string) ([]repo.RoomRow, error) {
+	st, en, err := parseRange(start, end, time.UTC)
+	if err != nil { return nil, err }
+	if f.FloorID != "" && f.BuildingID == "" { return nil, errors.New("floor_id needs building_id") }
+	return s.rooms.FindAvailable(f, st, en)
+}
+
+// ListAmenities returns the catalog that search filters refer to.
+func (s *searchService) ListAmenities() ([]repo.AmenityRow, error) { return s.amenities.List() }
+
+func (s *searchService) ListCampuses() ([]repo.CampusRow, error) { return s.locations.ListCampuses() }
+
+// ListBuildings returns the campus's buildings, or every building when
+// campusID is empty.
+func (s *searchService) ListBuildings(campusID string) ([]repo.BuildingRow, error) {
+	return s.locations.ListBuildings(campusID)
+}
 
-func NewSearchService(r repo.RoomRepo, _ repo.BookingRepo) SearchService {
-	return &searchService{rooms: r}
+func (s *searchService) GetBuilding(buildingID string) (repo.BuildingRow, error) {
+	b, err := s.locations.GetBuilding(buildingID)
+	if err != nil { return repo.BuildingRow{}, errors.New("building not found") }
+	return b, nil
 }
 
-func (s *searchService) FindAvailable(minCapacity int, start, end string) ([]repo.RoomRow, error) {
-	return s.rooms.FindAvailable(minCapacity, start, end)
+func (s *searchService) ListFloors(buildingID string) ([]repo.FloorRow, error) {
+	if _, err := s.locations.GetBuilding(buildingID); err != nil { return nil, errors.New("building not found") }
+	return s.locations.ListFloors(buildingID)
 }
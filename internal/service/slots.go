@@ -0,0 +1,199 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"studyroom/internal/repo"
+)
+
+// Bounds for FindNextSlots.
+const (
+	defaultSlotHorizon = 7 * 24 * time.Hour
+	maxSlotHorizon     = 31 * 24 * time.Hour
+	defaultSlotLimit   = 5
+	maxSlotLimit       = 50
+	maxSlotRooms       = 50
+)
+
+// SlotQuery asks for the earliest free slots of Duration in rooms matching
+// Filter. The search starts at From (RFC3339, or a local time read as UTC;
+// empty means now) and looks Horizon ahead. PreferFrom and PreferTo
+// ("hh:mm", local to each room) keep slots inside those hours of the day.
+type SlotQuery struct {
+	Filter     repo.RoomFilter
+	Duration   time.Duration
+	From       string
+	Horizon    time.Duration // defaults to 7 days, at most 31
+	PreferFrom string
+	PreferTo   string
+	Limit      int // defaults to 5, at most 50
+}
+
+type Slot struct {
+	RoomID   string    `json:"room_id"`
+	RoomName string    `json:"room_name"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+}
+
+// FindNextSlots works out each matching room's free time over the horizon
+// from its open time and bookings, buffers included, and returns the
+// earliest start in each free stretch that satisfies the room's policy,
+// ordered by start. Filter.MinCapacity also counts as the party size. The
+// rooms are loaded together, and at most maxSlotRooms may match.
+func (s *searchService) FindNextSlots(q SlotQuery) ([]Slot, error) {
+	if q.Duration <= 0 { return nil, errors.New("duration must be positive") }
+	if q.Horizon == 0 { q.Horizon = defaultSlotHorizon }
+	if q.Horizon < 0 || q.Horizon > maxSlotHorizon { return nil, fmt.Errorf("horizon must be at most %d days", int(maxSlotHorizon.Hours()/24)) }
+	if q.Limit <= 0 { q.Limit = defaultSlotLimit }
+	if q.Limit > maxSlotLimit { q.Limit = maxSlotLimit }
+	if q.Filter.FloorID != "" && q.Filter.BuildingID == "" { return nil, errors.New("floor_id needs building_id") }
+	var pf, pt int
+	prefer := q.PreferFrom != "" || q.PreferTo != ""
+	if prefer {
+		var err error
+		if pf, err = clockOrDefault(q.PreferFrom, "00:00"); err != nil { return nil, err }
+		if pt, err = clockOrDefault(q.PreferTo, "24:00"); err != nil { return nil, err }
+		if pt <= pf { return nil, errors.New("preferred hours end before they start") }
+		if time.Duration(pt-pf)*time.Minute < q.Duration { return nil, errors.New("duration does not fit in the preferred hours") }
+	}
+	now := time.Now()
+	from := now
+	if q.From != "" {
+		t, err := parseTime(q.From, time.UTC)
+		if err != nil { return nil, err }
+		if t.After(now) { from = t }
+	}
+	to := from.Add(q.Horizon)
+
+	matching, err := s.rooms.FindMatching(q.Filter)
+	if err != nil { return nil, err }
+	var ids []string
+	var pad time.Duration // the widest buffer of any room
+	for _, room := range matching {
+		if q.Filter.MinCapacity > 0 && checkPartySize(room, q.Filter.MinCapacity) != nil { continue }
+		ids = append(ids, room.ID)
+		if ps, _ := room.Policy.Pad(from, to); from.Sub(ps) > pad { pad = from.Sub(ps) }
+	}
+	if len(ids) > maxSlotRooms { return nil, fmt.Errorf("%d rooms match, at most %d can be searched; narrow the filter", len(ids), maxSlotRooms) }
+	out := []Slot{}
+	if len(ids) == 0 { return out, nil }
+	ots, err := s.rooms.OpenTimes(ids, from, to)
+	if err != nil { return nil, err }
+	all, err := s.book.ListOccupyingRooms(ids, from.Add(-pad), to.Add(pad))
+	if err != nil { return nil, err }
+	byRoom := map[string][]repo.BookingRow{}
+	for _, b := range all { byRoom[b.RoomID] = append(byRoom[b.RoomID], b) }
+
+	for _, ot := range ots {
+		room := ot.Room
+		p, loc := room.Policy, room.Location()
+		notice := now.Add(time.Duration(p.MinNoticeMin) * time.Minute)
+		ps, _ := p.Pad(from, to)
+		free := subtractBusy(ot.Open, byRoom[room.ID], from.Sub(ps))
+		if prefer { free = withinHours(free, loc, pf, pt) }
+		found := 0
+		for _, iv := range free {
+			st := iv.start
+			if st.Before(notice) { st = notice }
+			st = alignUp(st.In(loc), p.SlotGranularityMin)
+			en := st.Add(q.Duration)
+			if en.After(iv.end) || checkPolicy(p, st, en, now) != nil { continue }
+			out = append(out, Slot{RoomID: room.ID, RoomName: room.Name, Start: st, End: en})
+			// later stretches of this room can only come after its first q.Limit
+			if found++; found == q.Limit { break }
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	if len(out) > q.Limit { out = out[:q.Limit] }
+	return out, nil
+}
+
+// freeTime is the room's open time in [from, to) less its occupying
+// bookings, each widened by the room's buffers so that a new booking
+// starting or ending inside the result keeps its distance.
+func freeTime(rooms repo.RoomRepo, book repo.BookingRepo, room repo.RoomRow, from, to time.Time) ([]interval, error) {
+	open, _, err := rooms.OpenTime(room.ID, from, to)
+	if err != nil { return nil, err }
+	if len(open) == 0 { return nil, nil }
+	ps, pe := room.Policy.Pad(from, to)
+	bs, err := book.ListOccupying(room.ID, ps, pe)
+	if err != nil { return nil, err }
+	return subtractBusy(open, bs, from.Sub(ps)), nil
+}
+
+// subtractBusy takes the bookings bs, ordered by start and each widened by
+// pad on both sides, out of the open periods.
+func subtractBusy(open []repo.Period, bs []repo.BookingRow, pad time.Duration) []interval {
+	var out []interval
+	for _, o := range open {
+		at := o.Start
+		for _, b := range bs {
+			bst, ben := b.Start.Add(-pad), b.End.Add(pad)
+			if !ben.After(at) || !bst.Before(o.End) { continue }
+			if bst.After(at) { out = append(out, interval{at, bst}) }
+			at = ben
+		}
+		if o.End.After(at) { out = append(out, interval{at, o.End}) }
+	}
+	return out
+}
+
+// withinHours cuts each interval down to the minutes pf..pt of every local
+// day it spans.
+func withinHours(ivs []interval, loc *time.Location, pf, pt int) []interval {
+	var out []interval
+	for _, iv := range ivs {
+		ls := iv.start.In(loc)
+		for day := time.Date(ls.Year(), ls.Month(), ls.Day(), 0, 0, 0, 0, loc); day.Before(iv.end); day = day.AddDate(0, 0, 1) {
+			y, m, d := day.Date()
+			w := interval{time.Date(y, m, d, 0, pf, 0, 0, loc), time.Date(y, m, d, 0, pt, 0, 0, loc)}
+			if w.start.Before(iv.start) { w.start = iv.start }
+			if w.end.After(iv.end) { w.end = iv.end }
+			if w.end.After(w.start) { out = append(out, w) }
+		}
+	}
+	return out
+}
+
+// alignUp rounds t up to the next whole minute, or to the room's slot
+// granularity on the local clock, as checkPolicy counts it. On the day the
+// clocks change that differs from the time elapsed since midnight.
+func alignUp(t time.Time, granularityMin int) time.Time {
+	step := 1
+	if granularityMin > 0 { step = granularityMin }
+	mins := t.Hour()*60 + t.Minute()
+	if t.Second() != 0 || t.Nanosecond() != 0 { mins++ }
+	return alignStep(t, step, (mins+step-1)/step, 1)
+}
+
+// alignDown rounds t down the same way alignUp rounds up.
+func alignDown(t time.Time, granularityMin int) time.Time {
+	step := 1
+	if granularityMin > 0 { step = granularityMin }
+	return alignStep(t, step, (t.Hour()*60+t.Minute())/step, -1)
+}
+
+// alignStep returns the k-th step-minute boundary of t's local day, moving
+// on by dir while the clocks skip that time or it lies on the wrong side
+// of t. A local time the clocks repeat can be either of two instants.
+func alignStep(t time.Time, step, k, dir int) time.Time {
+	y, m, d := t.Date()
+	for ; ; k += dir {
+		want := (k*step%1440 + 1440) % 1440
+		out := time.Date(y, m, d, 0, k*step, 0, 0, t.Location())
+		for _, c := range []time.Time{out.Add(-time.Duration(dir) * time.Hour), out, out.Add(time.Duration(dir) * time.Hour)} {
+			if c.Hour()*60+c.Minute() != want { continue }
+			if (dir > 0 && !c.Before(t)) || (dir < 0 && !c.After(t)) { return c }
+		}
+	}
+}
+
+func clockOrDefault(v, def string) (int, error) {
+	if v == "" { v = def }
+	m, _, err := repo.HoursRange{Open: v, Close: v}.Minutes()
+	return m, err
+}
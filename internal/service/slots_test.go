@@ -0,0 +1,245 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"studyroom/internal/repo"
+)
+
+func TestAlign(t *testing.T) {
+	berlin := mustZone(t, "Europe/Berlin")
+	at := func(y int, m time.Month, d, h, min, sec int) time.Time {
+		return time.Date(y, m, d, h, min, sec, 0, berlin)
+	}
+	tests := []struct {
+		name     string
+		in       time.Time
+		gran     int
+		up, down string // local wall clock
+	}{
+		{"on the boundary", at(2026, 6, 1, 10, 30, 0), 30, "10:30", "10:30"},
+		{"between boundaries", at(2026, 6, 1, 10, 31, 0), 30, "11:00", "10:30"},
+		{"no granularity rounds to the minute", at(2026, 6, 1, 10, 31, 20), 0, "10:32", "10:31"},
+		{"seconds past a boundary", at(2026, 6, 1, 10, 30, 1), 15, "10:45", "10:30"},
+		{"counted from midnight", at(2026, 6, 1, 10, 50, 0), 45, "11:15", "10:30"},
+		{"up into the next day", at(2026, 6, 1, 23, 50, 0), 30, "00:00", "23:30"},
+		{"after spring forward", at(2026, 3, 29, 3, 5, 0), 45, "03:45", "03:00"},
+		{"later on the short day", at(2026, 3, 29, 10, 50, 0), 45, "11:15", "10:30"},
+		{"after fall back", at(2026, 10, 25, 4, 5, 0), 45, "04:30", "03:45"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up, down := alignUp(tt.in, tt.gran), alignDown(tt.in, tt.gran)
+			if got := up.Format("15:04"); got != tt.up || up.Before(tt.in) {
+				t.Errorf("alignUp = %s, want %s", up, tt.up)
+			}
+			if got := down.Format("15:04"); got != tt.down || down.After(tt.in) {
+				t.Errorf("alignDown = %s, want %s", down, tt.down)
+			}
+		})
+	}
+
+	// whatever the day, an aligned start passes the policy's own check
+	p := repo.RoomPolicy{SlotGranularityMin: 45}
+	for _, day := range []time.Time{at(2026, 3, 29, 0, 0, 0), at(2026, 10, 25, 0, 0, 0)} {
+		for tm := day; tm.Before(day.AddDate(0, 0, 1)); tm = tm.Add(7 * time.Minute) {
+			for _, st := range []time.Time{alignUp(tm, 45), alignDown(tm, 45)} {
+				if err := checkPolicy(p, st, st.Add(time.Hour), st.AddDate(0, 0, -1)); err != nil {
+					t.Errorf("aligned %s from %s: %v", st, tm, err)
+				}
+			}
+		}
+	}
+}
+
+func TestWithinHours(t *testing.T) {
+	berlin := mustZone(t, "Europe/Berlin")
+	utc := func(v string) time.Time {
+		t.Helper()
+		ts, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	tests := []struct {
+		name       string
+		start, end string // UTC
+		pf, pt     int
+		want       []string // UTC start/end pairs
+	}{
+		{
+			name:  "across spring forward keeps local hours",
+			start: "2026-03-27T23:00:00Z", end: "2026-03-30T22:00:00Z",
+			pf: 9 * 60, pt: 17 * 60,
+			want: []string{
+				"2026-03-28T08:00:00Z", "2026-03-28T16:00:00Z",
+				"2026-03-29T07:00:00Z", "2026-03-29T15:00:00Z",
+				"2026-03-30T07:00:00Z", "2026-03-30T15:00:00Z",
+			},
+		},
+		{
+			name:  "across fall back keeps local hours",
+			start: "2026-10-24T12:00:00Z", end: "2026-10-26T12:00:00Z",
+			pf: 9 * 60, pt: 17 * 60,
+			want: []string{
+				"2026-10-24T12:00:00Z", "2026-10-24T15:00:00Z",
+				"2026-10-25T08:00:00Z", "2026-10-25T16:00:00Z",
+				"2026-10-26T08:00:00Z", "2026-10-26T12:00:00Z",
+			},
+		},
+		{
+			name:  "whole short day",
+			start: "2026-03-28T23:00:00Z", end: "2026-03-29T22:00:00Z",
+			pf: 0, pt: 24 * 60,
+			want: []string{"2026-03-28T23:00:00Z", "2026-03-29T22:00:00Z"},
+		},
+		{
+			name:  "night hours inside the skipped hour",
+			start: "2026-03-28T23:00:00Z", end: "2026-03-29T22:00:00Z",
+			pf: 60, pt: 4 * 60,
+			want: []string{"2026-03-29T00:00:00Z", "2026-03-29T02:00:00Z"},
+		},
+		{
+			name:  "interval outside the hours",
+			start: "2026-06-01T18:00:00Z", end: "2026-06-01T20:00:00Z",
+			pf: 9 * 60, pt: 17 * 60,
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, iv := range withinHours([]interval{{utc(tt.start), utc(tt.end)}}, berlin, tt.pf, tt.pt) {
+				got = append(got, iv.start.UTC().Format(time.RFC3339), iv.end.UTC().Format(time.RFC3339))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFreeTime(t *testing.T) {
+	day := time.Date(2030, 1, 7, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+	rooms := &fakeRooms{open: map[string][]repo.Period{"r1": {{Start: at(8, 0), End: at(18, 0)}}}}
+	booked := func(h1, m1, h2, m2 int) repo.BookingRow {
+		return repo.BookingRow{RoomID: "r1", Start: at(h1, m1), End: at(h2, m2), Status: "confirmed"}
+	}
+	tests := []struct {
+		name   string
+		policy repo.RoomPolicy
+		rows   []repo.BookingRow
+		want   []string // "hh:mm-hh:mm"
+	}{
+		{
+			name: "no bookings",
+			want: []string{"08:00-18:00"},
+		},
+		{
+			name: "gaps between bookings",
+			rows: []repo.BookingRow{booked(10, 0, 11, 0), booked(12, 0, 13, 0)},
+			want: []string{"08:00-10:00", "11:00-12:00", "13:00-18:00"},
+		},
+		{
+			name:   "buffers widen each booking by both buffers",
+			policy: repo.RoomPolicy{BufferBeforeMin: 10, BufferAfterMin: 5},
+			rows:   []repo.BookingRow{booked(10, 0, 11, 0), booked(12, 0, 13, 0)},
+			want:   []string{"08:00-09:45", "11:15-11:45", "13:15-18:00"},
+		},
+		{
+			name:   "gap closed by buffers",
+			policy: repo.RoomPolicy{BufferAfterMin: 15},
+			rows:   []repo.BookingRow{booked(10, 0, 11, 0), booked(11, 20, 12, 0)},
+			want:   []string{"08:00-09:45", "12:15-18:00"},
+		},
+		{
+			name:   "booking outside open time reaches in by its buffer",
+			policy: repo.RoomPolicy{BufferAfterMin: 10},
+			rows:   []repo.BookingRow{booked(7, 0, 7, 55), booked(18, 5, 19, 0)},
+			want:   []string{"08:05-17:55"},
+		},
+		{
+			name: "cancelled bookings free their time",
+			rows: []repo.BookingRow{{RoomID: "r1", Start: at(10, 0), End: at(11, 0), Status: "cancelled"}},
+			want: []string{"08:00-18:00"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			room := repo.RoomRow{ID: "r1", Policy: tt.policy}
+			free, err := freeTime(rooms, &fakeBookings{rows: tt.rows}, room, day, day.AddDate(0, 0, 1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, iv := range free {
+				got = append(got, iv.start.Format("15:04")+"-"+iv.end.Format("15:04"))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindNextSlots(t *testing.T) {
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day()+10, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+	newService := func(n int) (*searchService, *fakeRooms, *fakeBookings) {
+		rooms := &fakeRooms{rooms: map[string]repo.RoomRow{}, open: map[string][]repo.Period{}}
+		for i := 1; i <= n; i++ {
+			id := fmt.Sprintf("r%d", i)
+			rooms.rooms[id] = repo.RoomRow{ID: id, Name: id, Capacity: 4 * i, Policy: repo.RoomPolicy{BufferAfterMin: 15 * (i % 2)}}
+			rooms.open[id] = []repo.Period{{Start: at(8, 0), End: at(18, 0)}}
+		}
+		book := &fakeBookings{rows: []repo.BookingRow{
+			{ID: "b1", RoomID: "r1", Start: at(8, 0), End: at(9, 0), Status: "confirmed"},
+			{ID: "b2", RoomID: "r2", Start: at(8, 0), End: at(10, 0), Status: "confirmed"},
+		}}
+		return &searchService{rooms: rooms, book: book}, rooms, book
+	}
+	tests := []struct {
+		name   string
+		filter repo.RoomFilter
+		limit  int
+		want   []string // "room hh:mm"
+	}{
+		{"earliest across rooms, buffers kept", repo.RoomFilter{}, 2, []string{"r1 09:15", "r2 10:00"}},
+		{"capacity leaves out the small room", repo.RoomFilter{MinCapacity: 6}, 5, []string{"r2 10:00"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, rooms, book := newService(2)
+			q := SlotQuery{Filter: tt.filter, Duration: time.Hour, From: at(8, 0).Format(time.RFC3339), Horizon: 24 * time.Hour, Limit: tt.limit}
+			slots, err := s.FindNextSlots(q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, sl := range slots {
+				got = append(got, sl.RoomID+" "+sl.Start.Format("15:04"))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			// the rooms are loaded together
+			if rooms.queries != 1 || book.queries != 1 {
+				t.Errorf("loaded open times %d times and bookings %d times, want once each", rooms.queries, book.queries)
+			}
+		})
+	}
+
+	t.Run("too many rooms", func(t *testing.T) {
+		s, _, _ := newService(maxSlotRooms + 1)
+		if _, err := s.FindNextSlots(SlotQuery{Duration: time.Hour}); err == nil || !strings.Contains(err.Error(), "narrow the filter") {
+			t.Errorf("got %v, want the room limit", err)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"studyroom/internal/repo"
+)
+
+// Why a room cannot take a booking at the requested time. CreateBooking
+// wraps both in a *SlotUnavailableError with alternatives.
+var (
+	ErrRoomClosed = errors.New("room not open in this interval")
+	ErrRoomBooked = errors.New("room already booked in this interval")
+)
+
+const (
+	maxSuggestions = 3
+	suggestWindow  = 7 * 24 * time.Hour // searched on either side of the requested start
+)
+
+// Suggestions are alternatives to a slot that could not be booked: the same
+// room at the nearest free times, and other rooms free at the requested time.
+type Suggestions struct {
+	SameRoom   []Slot `json:"same_room"`
+	OtherRooms []Slot `json:"other_rooms"`
+}
+
+// SlotUnavailableError is returned by CreateBooking when the room is closed
+// or already booked over the requested interval. Its message is the plain
+// reason, so callers that only show errors are unaffected.
+type SlotUnavailableError struct {
+	Err         error // ErrRoomClosed or ErrRoomBooked
+	Suggestions Suggestions
+}
+
+func (e *SlotUnavailableError) Error() string { return e.Err.Error() }
+func (e *SlotUnavailableError) Unwrap() error { return e.Err }
+
+// unavailable builds the error for reason with suggestions. Suggestions are
+// best effort: a lookup that fails leaves its list empty.
+func (s *bookingService) unavailable(reason error, room repo.RoomRow, start, end time.Time, party int) error {
+	e := &SlotUnavailableError{Err: reason, Suggestions: Suggestions{SameRoom: []Slot{}, OtherRooms: []Slot{}}}
+	if same, err := s.nearestSlots(room, start, end); err != nil {
+		log.Printf("suggest slots for room %s: %v", room.ID, err)
+	} else {
+		e.Suggestions.SameRoom = same
+	}
+	if other, err := s.otherRooms(room, start, end, party); err != nil {
+		log.Printf("suggest rooms for room %s: %v", room.ID, err)
+	} else {
+		e.Suggestions.OtherRooms = other
+	}
+	return e
+}
+
+// nearestSlots picks, from each stretch of the room's free time within
+// suggestWindow of start, the start closest to the requested one that keeps
+// its duration and meets the room's policy, and returns the closest few.
+func (s *bookingService) nearestSlots(room repo.RoomRow, start, end time.Time) ([]Slot, error) {
+	now := time.Now()
+	p, loc := room.Policy, room.Location()
+	dur := end.Sub(start)
+	from, to := start.Add(-suggestWindow), end.Add(suggestWindow)
+	if notice := now.Add(time.Duration(p.MinNoticeMin) * time.Minute); from.Before(notice) { from = notice }
+	out := []Slot{}
+	if !to.After(from) { return out, nil }
+	free, err := freeTime(s.rooms, s.book, room, from, to)
+	if err != nil { return nil, err }
+	for _, iv := range free {
+		st := start
+		if latest := iv.end.Add(-dur); st.After(latest) { st = alignDown(latest.In(loc), p.SlotGranularityMin) }
+		if st.Before(iv.start) { st = iv.start }
+		st = alignUp(st.In(loc), p.SlotGranularityMin)
+		en := st.Add(dur)
+		if en.After(iv.end) || checkPolicy(p, st, en, now) != nil { continue }
+		out = append(out, Slot{RoomID: room.ID, RoomName: room.Name, Start: st, End: en})
+	}
+	dist := func(t time.Time) time.Duration {
+		if d := t.Sub(start); d > 0 { return d }
+		return start.Sub(t)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return dist(out[i].Start) < dist(out[j].Start) })
+	if len(out) > maxSuggestions { out = out[:maxSuggestions] }
+	return out, nil
+}
+
+// otherRooms returns rooms that can take the party at the requested time,
+// closest in capacity to room first, then rooms in the same building.
+func (s *bookingService) otherRooms(room repo.RoomRow, start, end time.Time, party int) ([]Slot, error) {
+	rooms, err := s.rooms.FindAvailable(repo.RoomFilter{MinCapacity: party}, start, end)
+	if err != nil { return nil, err }
+	now := time.Now()
+	var cands []repo.RoomRow
+	for _, r := range rooms {
+		if r.ID == room.ID || checkPartySize(r, party) != nil { continue }
+		if checkPolicy(r.Policy, start.In(r.Location()), end.In(r.Location()), now) != nil { continue }
+		cands = append(cands, r)
+	}
+	gap := func(r repo.RoomRow) int {
+		if d := r.Capacity - room.Capacity; d > 0 { return d }
+		return room.Capacity - r.Capacity
+	}
+	sort.SliceStable(cands, func(i, j int) bool {
+		a, b := cands[i], cands[j]
+		if gap(a) != gap(b) { return gap(a) < gap(b) }
+		if sa, sb := a.BuildingID == room.BuildingID, b.BuildingID == room.BuildingID; sa != sb { return sa }
+		return a.Name < b.Name
+	})
+	out := []Slot{}
+	for _, r := range cands {
+		if len(out) == maxSuggestions { break }
+		loc := r.Location()
+		out = append(out, Slot{RoomID: r.ID, RoomName: r.Name, Start: start.In(loc), End: end.In(loc)})
+	}
+	return out, nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"studyroom/internal/repo"
+)
+
+func TestNearestSlots(t *testing.T) {
+	berlin := mustZone(t, "Europe/Berlin")
+	// a day far enough ahead that the whole search window is in the future
+	now := time.Now().In(berlin)
+	day := time.Date(now.Year(), now.Month(), now.Day()+10, 0, 0, 0, 0, berlin)
+	at := func(dayOffset, h, m int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day()+dayOffset, h, m, 0, 0, berlin)
+	}
+	// open 08:00-20:00 every day of the search window
+	var open []repo.Period
+	for d := -8; d <= 8; d++ {
+		open = append(open, repo.Period{Start: at(d, 8, 0), End: at(d, 20, 0)})
+	}
+	rooms := &fakeRooms{open: map[string][]repo.Period{"r1": open}}
+	booked := func(h1, m1, h2, m2 int) repo.BookingRow {
+		return repo.BookingRow{RoomID: "r1", Start: at(0, h1, m1), End: at(0, h2, m2), Status: "confirmed"}
+	}
+	// on other days the closest start is late the day before, early the day after
+	const dayBefore, dayAfter = "-1 19:00", "+1 08:00"
+
+	tests := []struct {
+		name       string
+		policy     repo.RoomPolicy
+		rows       []repo.BookingRow
+		start, end time.Time
+		want       []string // "±d hh:mm" relative to day
+	}{
+		{
+			name:  "either side of the booking, earlier first on a tie",
+			rows:  []repo.BookingRow{booked(10, 0, 11, 0)},
+			start: at(0, 10, 0), end: at(0, 11, 0),
+			want: []string{"+0 09:00", "+0 11:00", dayBefore},
+		},
+		{
+			name:  "closest first",
+			rows:  []repo.BookingRow{booked(9, 30, 11, 0)},
+			start: at(0, 10, 0), end: at(0, 11, 0),
+			want: []string{"+0 11:00", "+0 08:30", dayBefore},
+		},
+		{
+			name:   "buffers push the suggestions out to the next boundary",
+			policy: repo.RoomPolicy{BufferAfterMin: 15, SlotGranularityMin: 30},
+			rows:   []repo.BookingRow{booked(10, 0, 11, 0)},
+			start:  at(0, 10, 0), end: at(0, 11, 0),
+			want: []string{"+0 08:30", "+0 11:30", dayBefore},
+		},
+		{
+			name:   "a gap too short for the duration is skipped",
+			policy: repo.RoomPolicy{SlotGranularityMin: 15},
+			rows:   []repo.BookingRow{booked(8, 0, 9, 30), booked(10, 0, 12, 0), booked(12, 30, 20, 0)},
+			start:  at(0, 10, 0), end: at(0, 11, 0),
+			want: []string{dayBefore, dayAfter, "-2 19:00"},
+		},
+		{
+			name:   "starts stay on the granularity",
+			policy: repo.RoomPolicy{SlotGranularityMin: 20},
+			rows:   []repo.BookingRow{booked(9, 50, 11, 10)},
+			start:  at(0, 10, 0), end: at(0, 11, 0),
+			want: []string{"+0 08:40", "+0 11:20", dayBefore},
+		},
+		{
+			name:   "suggestions keep to the maximum duration",
+			policy: repo.RoomPolicy{MaxDurationMin: 30},
+			rows:   []repo.BookingRow{booked(10, 0, 11, 0)},
+			start:  at(0, 10, 0), end: at(0, 11, 0),
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &bookingService{rooms: rooms, book: &fakeBookings{rows: tt.rows}}
+			room := repo.RoomRow{ID: "r1", Name: "One", TimeZone: "Europe/Berlin", Policy: tt.policy}
+			slots, err := s.nearestSlots(room, tt.start, tt.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, sl := range slots {
+				st := sl.Start.In(berlin)
+				d := int(time.Date(st.Year(), st.Month(), st.Day(), 0, 0, 0, 0, time.UTC).
+					Sub(time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)).Hours() / 24)
+				got = append(got, fmt.Sprintf("%+d %s", d, st.Format("15:04")))
+				if sl.End.Sub(sl.Start) != tt.end.Sub(tt.start) {
+					t.Errorf("slot %s lasts %s, want %s", sl.Start, sl.End.Sub(sl.Start), tt.end.Sub(tt.start))
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
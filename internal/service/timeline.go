@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"studyroom/internal/repo"
+)
+
+// maxTimelineDays and maxTimelineRooms bound one RoomTimeline call.
+const (
+	maxTimelineDays  = 62
+	maxTimelineRooms = 50
+)
+
+// Timeline is a room's free/busy picture over a date range. Busy periods
+// lie inside open time unless a closure was added after they were booked.
+type Timeline struct {
+	RoomID   string        `json:"room_id"`
+	RoomName string        `json:"room_name"`
+	TimeZone string        `json:"time_zone,omitempty"`
+	Open     []repo.Period `json:"open"`
+	Closed   []repo.Period `json:"closed"`
+	Busy     []BusyPeriod  `json:"busy"`
+}
+
+// BusyPeriod is a booking or live hold. Who holds it is only shown to
+// admins; other viewers only learn whether it is their own.
+type BusyPeriod struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Status    string    `json:"status"` // confirmed | held
+	Mine      bool      `json:"mine,omitempty"`
+	BookingID string    `json:"booking_id,omitempty"` // admins and the viewer's own bookings
+	UserID    string    `json:"user_id,omitempty"`    // admins only
+}
+
+// RoomTimeline returns the open, closed and busy periods of each room for
+// the local dates from through to (inclusive, YYYY-MM-DD; to defaults to
+// from), read in each room's time zone. The rooms are loaded together, so
+// the number of queries does not grow with them.
+func (s *bookingService) RoomTimeline(viewerID string, admin bool, roomIDs []string, from, to string) ([]Timeline, error) {
+	if len(roomIDs) == 0 { return nil, errors.New("no rooms given") }
+	if len(roomIDs) > maxTimelineRooms { return nil, fmt.Errorf("more than %d rooms given", maxTimelineRooms) }
+	if to == "" { to = from }
+	fd, err := time.Parse(repo.DateLayout, from)
+	if err != nil { return nil, fmt.Errorf("invalid from %q: want YYYY-MM-DD", from) }
+	td, err := time.Parse(repo.DateLayout, to)
+	if err != nil { return nil, fmt.Errorf("invalid to %q: want YYYY-MM-DD", to) }
+	if td.Before(fd) { return nil, errors.New("to is before from") }
+	if td.Sub(fd) >= maxTimelineDays*24*time.Hour { return nil, fmt.Errorf("date range is longer than %d days", maxTimelineDays) }
+
+	// load the dates as read in every zone from UTC+14 to UTC-12 at once,
+	// then cut each room's own dates out of that
+	wideSt, wideEn := fd.Add(-14*time.Hour), td.AddDate(0, 0, 1).Add(12*time.Hour)
+	ots, err := s.rooms.OpenTimes(roomIDs, wideSt, wideEn)
+	if err != nil { return nil, err }
+	all, err := s.book.ListOccupyingRooms(roomIDs, wideSt, wideEn)
+	if err != nil { return nil, err }
+	byRoom := map[string][]repo.BookingRow{}
+	for _, b := range all { byRoom[b.RoomID] = append(byRoom[b.RoomID], b) }
+
+	out := make([]Timeline, 0, len(ots))
+	for _, ot := range ots {
+		room := ot.Room
+		loc := room.Location()
+		st := time.Date(fd.Year(), fd.Month(), fd.Day(), 0, 0, 0, 0, loc)
+		en := time.Date(td.Year(), td.Month(), td.Day()+1, 0, 0, 0, 0, loc)
+
+		tl := Timeline{RoomID: room.ID, RoomName: room.Name, TimeZone: room.TimeZone, Open: []repo.Period{}, Closed: clipPeriods(ot.Closed, st, en), Busy: []BusyPeriod{}}
+		if !room.Archived { tl.Open = clipPeriods(ot.Open, st, en) }
+
+		for _, b := range byRoom[room.ID] {
+			if !b.End.After(st) || !b.Start.Before(en) { continue }
+			bp := BusyPeriod{Start: b.Start.In(loc), End: b.End.In(loc), Status: b.Status, Mine: b.UserID == viewerID}
+			for _, a := range b.Attendees {
+				if a == viewerID { bp.Mine = true }
+			}
+			if admin || b.UserID == viewerID { bp.BookingID = b.ID }
+			if admin { bp.UserID = b.UserID }
+			tl.Busy = append(tl.Busy, bp)
+		}
+		out = append(out, tl)
+	}
+	return out, nil
+}
+
+// clipPeriods returns the parts of ps within [start, end).
+func clipPeriods(ps []repo.Period, start, end time.Time) []repo.Period {
+	out := []repo.Period{}
+	for _, p := range ps {
+		if p.Start.Before(start) { p.Start = start }
+		if p.End.After(end) { p.End = end }
+		if p.End.After(p.Start) { out = append(out, p) }
+	}
+	return out
+}
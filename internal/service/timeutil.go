@@ -0,0 +1,63 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"studyroom/internal/repo"
+)
+
+// localLayouts are accepted for wall-clock times given without a UTC
+// offset; they are read in the time zone of the room being booked.
+var localLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+// parseTime reads an RFC3339 timestamp, or a local time without offset in
+// loc, and returns it in loc. Comparing the result is offset independent.
+func parseTime(v string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil { return t.In(loc), nil }
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, v, loc); err == nil { return t, nil }
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: want RFC3339 or a local YYYY-MM-DDThh:mm[:ss]", v)
+}
+
+// parseRange parses [start, end) in loc and rejects empty or reversed ranges.
+func parseRange(start, end string, loc *time.Location) (time.Time, time.Time, error) {
+	st, err := parseTime(start, loc)
+	if err != nil { return time.Time{}, time.Time{}, err }
+	en, err := parseTime(end, loc)
+	if err != nil { return time.Time{}, time.Time{}, err }
+	if !en.After(st) { return time.Time{}, time.Time{}, errors.New("invalid time range") }
+	return st, en, nil
+}
+
+// roomRange loads roomID and parses [start, end) in the room's time zone.
+func (s *bookingService) roomRange(roomID string, start, end string) (repo.RoomRow, time.Time, time.Time, error) {
+	room, err := s.rooms.GetByID(roomID)
+	if err != nil { return repo.RoomRow{}, time.Time{}, time.Time{}, errors.New("room not found") }
+	st, en, err := parseRange(start, end, room.Location())
+	if err != nil { return repo.RoomRow{}, time.Time{}, time.Time{}, err }
+	return room, st, en, nil
+}
+
+// localize renders booking times in the time zone of their room.
+func (s *bookingService) localize(rows []repo.BookingRow) []repo.BookingRow {
+	locs := map[string]*time.Location{}
+	for i := range rows {
+		loc, ok := locs[rows[i].RoomID]
+		if !ok {
+			loc = time.UTC
+			if room, err := s.rooms.GetByID(rows[i].RoomID); err == nil { loc = room.Location() }
+			locs[rows[i].RoomID] = loc
+		}
+		rows[i].Start, rows[i].End = rows[i].Start.In(loc), rows[i].End.In(loc)
+	}
+	return rows
+}
+
+// roomTime formats t in the room's time zone for messages to users.
+func (s *bookingService) roomTime(roomID string, t time.Time) string {
+	if room, err := s.rooms.GetByID(roomID); err == nil { t = t.In(room.Location()) }
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func mustZone(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("load %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestParseTime(t *testing.T) {
+	berlin := mustZone(t, "Europe/Berlin")
+	tests := []struct {
+		name, in string
+		want     string // instant in UTC
+	}{
+		{"UTC with Z", "2026-03-29T08:00:00Z", "2026-03-29T08:00:00Z"},
+		{"same instant with offset", "2026-03-29T10:00:00+02:00", "2026-03-29T08:00:00Z"},
+		{"offset unlike the room's", "2026-03-29T04:00:00-04:00", "2026-03-29T08:00:00Z"},
+		{"local before spring forward", "2026-03-29T01:30", "2026-03-29T00:30:00Z"},
+		{"local after spring forward", "2026-03-29T03:30", "2026-03-29T01:30:00Z"},
+		{"local with seconds", "2026-03-29T10:00:30", "2026-03-29T08:00:30Z"},
+		{"local before fall back", "2026-10-25T01:30", "2026-10-24T23:30:00Z"},
+		{"local after fall back", "2026-10-25T03:30", "2026-10-25T02:30:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTime(tt.in, berlin)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, _ := time.Parse(time.RFC3339, tt.want)
+			if !got.Equal(want) {
+				t.Errorf("parseTime(%q) = %s, want %s", tt.in, got.UTC().Format(time.RFC3339), tt.want)
+			}
+			if got.Location() != berlin {
+				t.Errorf("parseTime(%q) is in %s, want it in the room's zone", tt.in, got.Location())
+			}
+		})
+	}
+
+	for _, in := range []string{"", "tomorrow", "2026-03-29", "2026-03-29 10:00", "2026-13-01T10:00"} {
+		if _, err := parseTime(in, berlin); err == nil {
+			t.Errorf("parseTime(%q) accepted an invalid time", in)
+		}
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	berlin := mustZone(t, "Europe/Berlin")
+	tests := []struct {
+		name, start, end string
+		want             time.Duration
+		wantErr          bool
+	}{
+		{"ordinary day", "2026-03-28T00:00", "2026-03-29T00:00", 24 * time.Hour, false},
+		{"spring-forward day is short", "2026-03-29T00:00", "2026-03-30T00:00", 23 * time.Hour, false},
+		{"fall-back day is long", "2026-10-25T00:00", "2026-10-26T00:00", 25 * time.Hour, false},
+		{"across the skipped hour", "2026-03-29T01:00", "2026-03-29T04:00", 2 * time.Hour, false},
+		{"mixed offset and local", "2026-03-29T08:00:00Z", "2026-03-29T11:00", time.Hour, false},
+		{"Z and offset for one instant", "2026-03-29T08:00:00Z", "2026-03-29T10:00:00+02:00", 0, true},
+		{"reversed", "2026-03-29T11:00", "2026-03-29T10:00", 0, true},
+		{"bad end", "2026-03-29T10:00", "noon", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, en, err := parseRange(tt.start, tt.end, berlin)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseRange(%q, %q) = %s..%s, want an error", tt.start, tt.end, st, en)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := en.Sub(st); got != tt.want {
+				t.Errorf("parseRange(%q, %q) spans %s, want %s", tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+}
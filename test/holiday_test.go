@@ -0,0 +1,43 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"testing"
+
+	"studyroom/internal/repo"
+	"studyroom/internal/service"
+)
+
+func TestReimportingHolidaysAddsNothing(t *testing.T) {
+	svc, _ := newBookingService(t, service.BookingConfig{})
+	hs, err := service.ParseHolidayCalendar("BEGIN:VEVENT\nSUMMARY:Winter break\nDTSTART;VALUE=DATE:20301230\nDTEND;VALUE=DATE:20310102\nEND:VEVENT\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _, err := svc.AddHolidays(hs, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _, err := svc.AddHolidays(hs, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range first {
+		if first[i] != again[i] {
+			t.Errorf("holiday %d saved again as %s, want the existing %s", i, again[i], first[i])
+		}
+	}
+	// the same date under another name is a separate holiday
+	if ids, _, err := svc.AddHolidays([]repo.HolidayRow{{Date: "2030-12-31", Name: "New Year's Eve"}}, ""); err != nil || ids[0] == first[1] {
+		t.Errorf("differently named holiday: ids %v, err %v", ids, err)
+	}
+	saved, err := svc.ListHolidays("2030-12-30", "2031-01-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 4 {
+		t.Errorf("saved %d holidays, want 4", len(saved))
+	}
+}
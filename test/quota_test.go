@@ -0,0 +1,76 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"studyroom/internal/repo"
+	"studyroom/internal/service"
+)
+
+// newBookingService wires a booking service to the scratch database.
+func newBookingService(tb testing.TB, cfg service.BookingConfig) (service.BookingService, repo.UserRepo) {
+	d := scratchDB(tb)
+	users := repo.NewUserRepoMongo(d)
+	svc := service.NewBookingService(repo.NewRoomRepoMongo(d), repo.NewBookingRepoMongo(d), repo.NewWaitlistRepoMongo(d),
+		repo.NewSeriesRepoMongo(d), repo.NewGroupRepoMongo(d), repo.NewHolidayRepoMongo(d), repo.NewAmenityRepoMongo(d),
+		repo.NewLocationRepoMongo(d), users, repo.NewNotificationRepoMongo(d), cfg)
+	return svc, users
+}
+
+// openRoom creates a room in UTC that is open around the clock.
+func openRoom(tb testing.TB, svc service.BookingService, name string, capacity int) string {
+	return openRoomIn(tb, svc, name, capacity, "UTC")
+}
+
+// openRoomIn is openRoom in the time zone zone.
+func openRoomIn(tb testing.TB, svc service.BookingService, name string, capacity int, zone string) string {
+	id, err := svc.CreateRoom(name, capacity, zone, "", "")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	h := repo.OpeningHours{EffectiveFrom: "2000-01-01"}
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		h.Ranges = append(h.Ranges, repo.HoursRange{Weekday: wd, Open: "00:00", Close: "24:00"})
+	}
+	if _, err := svc.AddRoomHours(id, h); err != nil {
+		tb.Fatal(err)
+	}
+	return id
+}
+
+// TestHoldsCountTowardsQuota checks that live holds use up the active
+// booking quota, so holding cannot get round it.
+func TestHoldsCountTowardsQuota(t *testing.T) {
+	cfg := service.DefaultBookingConfig()
+	cfg.MaxActiveBookings = 2
+	svc, users := newBookingService(t, cfg)
+	user, err := users.Create("holder@example.com", []byte("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	room := openRoom(t, svc, "Quota Room", 4)
+
+	day := time.Now().UTC().AddDate(0, 0, 7).Truncate(24 * time.Hour)
+	slot := func(h int) (string, string) {
+		st := day.Add(time.Duration(h) * time.Hour)
+		return st.Format(time.RFC3339), st.Add(time.Hour).Format(time.RFC3339)
+	}
+	for h := 9; h < 9+cfg.MaxActiveBookings; h++ {
+		st, en := slot(h)
+		if _, _, err := svc.HoldSlot(room, user, st, en, service.BookingDetails{}); err != nil {
+			t.Fatalf("hold %d: %v", h-8, err)
+		}
+	}
+	st, en := slot(9 + cfg.MaxActiveBookings)
+	if _, _, err := svc.HoldSlot(room, user, st, en, service.BookingDetails{}); !errors.Is(err, service.ErrQuotaExceeded) {
+		t.Fatalf("hold over the quota: got %v, want %v", err, service.ErrQuotaExceeded)
+	}
+	if _, err := svc.CreateBooking(room, user, st, en, service.BookingDetails{}); !errors.Is(err, service.ErrQuotaExceeded) {
+		t.Fatalf("booking next to live holds: got %v, want %v", err, service.ErrQuotaExceeded)
+	}
+}
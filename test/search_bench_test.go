@@ -0,0 +1,99 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"studyroom/internal/repo"
+)
+
+const benchRooms = 1500
+
+// seedSearchDB fills a scratch database with benchRooms rooms, each with
+// weekly hours, a booking and, for every tenth room, a closure in the
+// searched interval.
+func seedSearchDB(b *testing.B) (*mongo.Database, time.Time, time.Time) {
+	d := scratchDB(b)
+	day := time.Now().UTC().AddDate(0, 0, 7).Truncate(24 * time.Hour)
+	start, end := day.Add(10*time.Hour), day.Add(11*time.Hour)
+	var rooms, hours, bookings, windows []interface{}
+	for i := 0; i < benchRooms; i++ {
+		id := primitive.NewObjectID()
+		rooms = append(rooms, bson.M{
+			"_id": id, "name": fmt.Sprintf("Room %04d", i), "capacity": 2 + i%12,
+			"policy": bson.M{"buffer_before_min": i % 3 * 5},
+		})
+		var ranges []bson.M
+		for wd := 0; wd < 7; wd++ {
+			ranges = append(ranges, bson.M{"weekday": wd, "open": "08:00", "close": "20:00"})
+		}
+		hours = append(hours, bson.M{"_id": primitive.NewObjectID(), "room_id": id, "effective_from": "2000-01-01", "ranges": ranges})
+		// every third room is booked in the searched hour, the rest just before it
+		bs := start.Add(-2 * time.Hour)
+		if i%3 == 0 {
+			bs = start
+		}
+		bookings = append(bookings, bson.M{
+			"room_id": id, "user_id": primitive.NewObjectID(), "status": "confirmed",
+			"start_at": bs, "end_at": bs.Add(time.Hour), "party_size": 1,
+		})
+		if i%10 == 0 {
+			windows = append(windows, bson.M{"room_id": id, "start_at": day, "end_at": day.Add(24 * time.Hour), "is_open": false})
+		}
+	}
+	ctx := context.Background()
+	for coll, docs := range map[string][]interface{}{"rooms": rooms, "room_hours": hours, "bookings": bookings, "room_schedules": windows} {
+		if _, err := d.Collection(coll).InsertMany(ctx, docs); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return d, start, end
+}
+
+// BenchmarkFindAvailable measures the batched search.
+func BenchmarkFindAvailable(b *testing.B) {
+	d, start, end := seedSearchDB(b)
+	rooms := repo.NewRoomRepoMongo(d)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := rooms.FindAvailable(repo.RoomFilter{MinCapacity: 4}, start, end); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkFindAvailablePerRoom is the baseline: the same answer built with
+// one open-time check and one booking query per room.
+func BenchmarkFindAvailablePerRoom(b *testing.B) {
+	d, start, end := seedSearchDB(b)
+	rooms, bookings := repo.NewRoomRepoMongo(d), repo.NewBookingRepoMongo(d)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cands, err := rooms.FindMatching(repo.RoomFilter{MinCapacity: 4})
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, r := range cands {
+			open, err := rooms.IsWithinOpenSchedule(r.ID, start, end)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if !open {
+				continue
+			}
+			ps, pe := r.Policy.Pad(start, end)
+			if _, err := bookings.ListOccupying(r.ID, ps, pe); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
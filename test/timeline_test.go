@@ -0,0 +1,57 @@
+//go:build integration
+// +build integration
+
+package test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"studyroom/internal/service"
+)
+
+// TestTimelineAcrossZones loads rooms at both ends of the zone range in one
+// call and checks that each gets exactly its own local day.
+func TestTimelineAcrossZones(t *testing.T) {
+	svc, users := newBookingService(t, service.DefaultBookingConfig())
+	user, err := users.Create("viewer@example.com", []byte("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zones := []string{"Pacific/Kiritimati", "UTC", "Pacific/Pago_Pago"}
+	var rooms []string
+	for _, z := range zones {
+		rooms = append(rooms, openRoomIn(t, svc, "Room "+z, 4, z))
+	}
+	day := time.Now().UTC().AddDate(0, 0, 7).Format("2006-01-02")
+	if _, err := svc.CreateBooking(rooms[1], user, day+"T10:00", day+"T11:00", service.BookingDetails{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tls, err := svc.RoomTimeline(user, false, rooms, day, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tl := range tls {
+		loc, _ := time.LoadLocation(zones[i])
+		st, _ := time.ParseInLocation("2006-01-02", day, loc)
+		if len(tl.Open) != 1 || !tl.Open[0].Start.Equal(st) || !tl.Open[0].End.Equal(st.AddDate(0, 0, 1)) {
+			t.Errorf("%s: open %v, want the whole local day %s", zones[i], tl.Open, day)
+		}
+		if want := map[bool]int{true: 1, false: 0}[i == 1]; len(tl.Busy) != want {
+			t.Errorf("%s: %d busy periods, want %d", zones[i], len(tl.Busy), want)
+		}
+	}
+
+	many := make([]string, 51)
+	for i := range many {
+		many[i] = rooms[0]
+	}
+	if _, err := svc.RoomTimeline(user, false, many, day, ""); err == nil {
+		t.Error("a timeline of 51 rooms was not refused")
+	}
+	if _, err := svc.RoomTimeline(user, false, []string{rooms[0], fmt.Sprintf("%024x", 1)}, day, ""); err == nil {
+		t.Error("an unknown room was not reported")
+	}
+}